@@ -0,0 +1,570 @@
+package decouple
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config holds the settings used when looking up variables. The
+// package-level functions (GetString, GetInt, etc.) use a default
+// Config; use New to create an independent one.
+type Config struct {
+	prefix       string
+	environ      map[string]string
+	emptyAsUnset bool
+	prefixDedup  bool
+	fileDir      string
+	secretTrim   string
+	sliceElement func(string) string
+	dropEmpty    bool
+	comments     bool
+	valueCase    string
+	errorHandler func(name, value string, err error)
+	observer     func(name, resolvedName, value string, fromEnv bool)
+
+	// aliases maps new variable names to the deprecated names they
+	// replace.
+	aliases            map[string]string
+	deprecationHandler func(oldName, newName string)
+
+	// defaulted is nil unless default tracking is enabled. It is a
+	// pointer so that copies of c record into the same set.
+	defaulted *defaultedKeys
+}
+
+// defaultedKeys is the set of names reported by DefaultedKeys.
+type defaultedKeys struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// An Option configures a Config created with New.
+type Option func(*Config)
+
+var defaultConfig = New()
+
+// New returns a new Config configured with the given options.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithPrefix("APP_"))
+//	port, _ := cfg.GetInt("PORT", 8080)
+func New(opts ...Option) *Config {
+	c := &Config{}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithPrefix sets the prefix that will be applied when looking for
+// variables. See SetPrefix for details.
+func WithPrefix(prefix string) Option {
+	return func(c *Config) {
+		c.prefix = prefix
+	}
+}
+
+// WithEnviron makes the Config look up variables exclusively in env
+// rather than in the process environment. Names are prefixed before
+// they are looked up in env.
+//
+// Example:
+//
+//	cfg := decouple.New(
+//		decouple.WithEnviron(map[string]string{"APP_PORT": "9000"}),
+//		decouple.WithPrefix("APP_"),
+//	)
+//	port, _ := cfg.GetInt("PORT", 8080)
+func WithEnviron(env map[string]string) Option {
+	return func(c *Config) {
+		c.environ = env
+	}
+}
+
+// WithEmptyAsUnset controls how variables that are set to the empty
+// string are treated. When enabled, such variables are reported as
+// not existing, so that all Get* functions return their default
+// values. It is disabled by default.
+func WithEmptyAsUnset(enabled bool) Option {
+	return func(c *Config) {
+		c.emptyAsUnset = enabled
+	}
+}
+
+// WithErrorHandler registers a function that is called whenever a
+// variable exists but its value cannot be converted to the requested
+// type. The function receives the variable name (without the
+// prefix), the raw value, and the conversion error. It is not called
+// for variables that do not exist.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithErrorHandler(
+//		func(name, value string, err error) {
+//			log.Printf("%s=%s could not be parsed (%v), using default", name, value, err)
+//		}))
+func WithErrorHandler(fn func(name, value string, err error)) Option {
+	return func(c *Config) {
+		c.errorHandler = fn
+	}
+}
+
+// WithObserver registers a function that is called every time c
+// looks up a variable, whether or not the variable exists. The
+// function receives the name that was requested, the name of the
+// variable that was actually consulted (with the prefix applied, or
+// the deprecated name if the value was found using an alias), the
+// raw value (empty if the variable does not exist), and whether the
+// variable was found. This can be used to collect metrics on which
+// settings are used and which fall back to their defaults.
+//
+// The function is called once for each call to a Get* function, even
+// when that function consults more than one variable (as GetStringOr,
+// GetSecret and GetIndexedStrings do, for example); it then reports
+// the variable that supplied the value. Variables referenced by the
+// values read by GetStringExpanded and GetStringTemplate are not
+// reported.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithObserver(
+//		func(name, resolvedName, value string, fromEnv bool) {
+//			lookups.WithLabelValues(name, strconv.FormatBool(fromEnv)).Inc()
+//		}))
+func WithObserver(fn func(name, resolvedName, value string, fromEnv bool)) Option {
+	return func(c *Config) {
+		c.observer = fn
+	}
+}
+
+// WithPrefixDedup controls whether the prefix is applied to names
+// that already start with it. When enabled, a name such as "APP_PORT"
+// is looked up as-is if the prefix is "APP_", while "PORT" is still
+// looked up as "APP_PORT". It is disabled by default, in which case
+// "APP_PORT" would be looked up as "APP_APP_PORT".
+func WithPrefixDedup(enabled bool) Option {
+	return func(c *Config) {
+		c.prefixDedup = enabled
+	}
+}
+
+// WithFileDir makes the Config look for variables in files in the
+// directory path, such as a Kubernetes ConfigMap or Secret mounted as
+// a volume. When a variable is not found in the environment, c reads
+// the file in path named after the variable (with the prefix
+// applied) and uses its contents, trimmed as configured with
+// WithSecretTrim. Variables set in the environment take precedence
+// over files. Names that contain a path separator or ".." are never
+// looked up in path.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithFileDir("/etc/config"))
+//	dsn, _ := cfg.GetString("DATABASE_DSN", "")
+func WithFileDir(path string) Option {
+	return func(c *Config) {
+		c.fileDir = path
+	}
+}
+
+// Trim modes for WithSecretTrim.
+const (
+	SecretTrimNewline = "newline"
+	SecretTrimSpace   = "space"
+	SecretTrimNone    = "none"
+)
+
+// WithSecretTrim controls how values read from files (by GetSecret,
+// GetWithSources and WithFileDir) are trimmed. With
+// SecretTrimNewline, the default, a single trailing "\n" or "\r\n" is
+// removed, so that the newline most editors add is ignored while
+// other whitespace is preserved. SecretTrimSpace removes all leading
+// and trailing whitespace, and SecretTrimNone uses the contents
+// unchanged. WithSecretTrim panics if mode is not one of these.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithSecretTrim(decouple.SecretTrimNone))
+//	token, _ := cfg.GetSecret("API_TOKEN", "")
+func WithSecretTrim(mode string) Option {
+	switch mode {
+	case SecretTrimNewline, SecretTrimSpace, SecretTrimNone:
+	default:
+		panic(fmt.Sprintf("decouple: unknown secret trim mode %q", mode))
+	}
+
+	return func(c *Config) {
+		c.secretTrim = mode
+	}
+}
+
+// WithSliceElementTransform registers a function that is applied to
+// every element of the lists returned by GetCSVString and the other
+// functions that return a []string, such as strings.ToLower to make
+// allowlists case-insensitive. Where a function trims elements or
+// removes duplicates, fn is applied after trimming and before
+// duplicates are removed. It is not applied to the file names
+// returned by GetGlob.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithSliceElementTransform(strings.ToLower))
+//	hosts, _ := cfg.GetCSVString("ALLOWED_HOSTS", nil)
+func WithSliceElementTransform(fn func(string) string) Option {
+	return func(c *Config) {
+		c.sliceElement = fn
+	}
+}
+
+// WithDropEmptyListElements controls whether empty elements, and
+// elements that contain only whitespace, are removed from the lists
+// returned by GetCSVString and the other functions that parse a
+// variable as a list. For example, "a,,b" is returned as ["a", "b"]
+// rather than ["a", "", "b"], and ", ," as an empty list. A variable
+// that is set to the empty string is still treated as unparseable by
+// the CSV functions. GetGlob, which returns file names rather than
+// parsing the variable, is not affected. It is disabled by default.
+func WithDropEmptyListElements(enabled bool) Option {
+	return func(c *Config) {
+		c.dropEmpty = enabled
+	}
+}
+
+// WithValueComments controls whether a trailing comment is removed
+// from the value of every variable before it is used. A comment
+// starts at the first "#" that is not inside double quotes and is
+// either at the start of the value or preceded by whitespace; it is
+// removed along with that whitespace, so that "8080 # default" is
+// read as "8080" while "pa#ss" is left alone. It is disabled by
+// default.
+func WithValueComments(enabled bool) Option {
+	return func(c *Config) {
+		c.comments = enabled
+	}
+}
+
+// Case modes for WithValueCase.
+const (
+	ValueCaseLower = "lower"
+	ValueCaseUpper = "upper"
+	ValueCaseNone  = "none"
+)
+
+// WithValueCase makes GetString, and the functions that compare a
+// value against a list of choices (GetStringChoices, GetChoiceIndex,
+// etc.), convert the value to lower case (ValueCaseLower) or upper
+// case (ValueCaseUpper) before comparing or returning it, so that,
+// for example, "US-East-1" and "us-east-1" are treated the same.
+// Default values are returned unchanged. The default, ValueCaseNone,
+// leaves values as they are. WithValueCase panics if mode is not one
+// of these.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithValueCase(decouple.ValueCaseLower))
+//	region, _ := cfg.GetStringChoices("REGION", "us-east-1", []string{"us-east-1", "eu-west-1"})
+func WithValueCase(mode string) Option {
+	switch mode {
+	case ValueCaseLower, ValueCaseUpper, ValueCaseNone, "":
+	default:
+		panic(fmt.Sprintf("decouple: unknown value case mode %q", mode))
+	}
+
+	return func(c *Config) {
+		c.valueCase = mode
+	}
+}
+
+// WithDefaultTracking controls whether the Config records the names
+// of variables that fall back to their default values: those that are
+// looked up but do not exist, and those whose values cannot be
+// converted to the requested type. The names are available from
+// DefaultedKeys. It is disabled by default.
+//
+// Example:
+//
+//	cfg := decouple.New(decouple.WithDefaultTracking(true))
+//	...
+//	log.Printf("settings using defaults: %v", cfg.DefaultedKeys())
+func WithDefaultTracking(enabled bool) Option {
+	return func(c *Config) {
+		if enabled {
+			c.defaulted = &defaultedKeys{names: make(map[string]bool)}
+		} else {
+			c.defaulted = nil
+		}
+	}
+}
+
+// SetPrefix sets the prefix that will be applied when looking for
+// variables using c.
+func (c *Config) SetPrefix(prefix string) {
+	c.prefix = prefix
+}
+
+// AliasDeprecated registers oldName as a deprecated name for
+// newName. When newName is not set, lookups of newName fall back to
+// oldName; if the value is found there, the handler registered with
+// SetDeprecationHandler is called.
+func (c *Config) AliasDeprecated(oldName, newName string) {
+	if c.aliases == nil {
+		c.aliases = make(map[string]string)
+	}
+
+	c.aliases[newName] = oldName
+}
+
+// SetDeprecationHandler registers a function that is called whenever
+// a variable is resolved using a deprecated name registered with
+// AliasDeprecated.
+func (c *Config) SetDeprecationHandler(fn func(oldName, newName string)) {
+	c.deprecationHandler = fn
+}
+
+// LookupEnv looks up the named variable after applying the prefix
+// configured for c. If c was created using WithEnviron, the variable
+// is looked up in the supplied map; otherwise it is looked up in the
+// process environment. If the variable is not set but a deprecated
+// name has been registered for it with AliasDeprecated, the
+// deprecated name is looked up instead.
+func (c *Config) LookupEnv(name string) (string, bool) {
+	val, resolvedName, exists := c.lookup(name)
+	c.observe(name, resolvedName, val, exists)
+
+	if !exists {
+		c.recordDefault(name)
+	}
+
+	return val, exists
+}
+
+// lookup is like LookupEnv, but does not call the observer configured
+// with WithObserver or record a missing variable as defaulted, and
+// also returns the name of the variable that was consulted. Getters
+// that look up more than one variable use it so that they can report
+// a single lookup, and record a default only when they return one.
+func (c *Config) lookup(name string) (string, string, bool) {
+	resolvedName := c.fullName(name)
+	val, exists := c.lookupEnv(name)
+
+	if oldName, ok := c.aliases[name]; ok && !exists {
+		if val, exists = c.lookupEnv(oldName); exists {
+			resolvedName = c.fullName(oldName)
+			if c.deprecationHandler != nil {
+				c.deprecationHandler(oldName, name)
+			}
+		}
+	}
+
+	return val, resolvedName, exists
+}
+
+// observe reports a lookup to the observer configured with
+// WithObserver, if any.
+func (c *Config) observe(name, resolvedName, val string, exists bool) {
+	if c.observer != nil {
+		c.observer(name, resolvedName, val, exists)
+	}
+}
+
+// lookupEnv looks up a single variable name, without considering
+// deprecated aliases.
+func (c *Config) lookupEnv(name string) (string, bool) {
+	name = c.fullName(name)
+
+	var val string
+	var exists bool
+	if c.environ != nil {
+		val, exists = c.environ[name]
+	} else {
+		val, exists = os.LookupEnv(name)
+	}
+
+	if !exists && c.fileDir != "" && isFileName(name) {
+		if content, err := os.ReadFile(filepath.Join(c.fileDir, name)); err == nil {
+			val, exists = c.trimSecret(string(content)), true
+		}
+	}
+
+	if exists && c.comments {
+		val = stripComment(val, true)
+	}
+
+	if exists && val == "" && c.emptyAsUnset {
+		return "", false
+	}
+
+	return val, exists
+}
+
+// isFileName reports whether name can be used as a file name inside
+// the directory configured with WithFileDir. Names containing path
+// separators or ".." are rejected so that a lookup cannot escape that
+// directory.
+func isFileName(name string) bool {
+	return name != "" && name == filepath.Base(name) &&
+		!strings.ContainsAny(name, `/\`) && !strings.Contains(name, "..")
+}
+
+// DumpAll returns all variables whose names start with the prefix
+// configured for c, keyed by name with the prefix removed. If c was
+// created using WithEnviron, the supplied map is searched instead of
+// the process environment.
+func (c *Config) DumpAll() map[string]string {
+	env := c.environ
+	if env == nil {
+		env = make(map[string]string)
+		for _, entry := range os.Environ() {
+			name, val := splitEnviron(entry)
+			env[name] = val
+		}
+	}
+
+	ret := make(map[string]string)
+	for name, val := range env {
+		if strings.HasPrefix(name, c.prefix) {
+			ret[strings.TrimPrefix(name, c.prefix)] = val
+		}
+	}
+
+	return ret
+}
+
+// DefaultedKeys returns the sorted names of the variables that have
+// fallen back to their default values since c was created, if c was
+// created with WithDefaultTracking. Otherwise, it returns nil.
+func (c *Config) DefaultedKeys() []string {
+	if c.defaulted == nil {
+		return nil
+	}
+
+	c.defaulted.mu.Lock()
+	defer c.defaulted.mu.Unlock()
+
+	ret := make([]string, 0, len(c.defaulted.names))
+	for name := range c.defaulted.names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// recordDefault records that the named variable fell back to its
+// default value, if default tracking is enabled.
+func (c *Config) recordDefault(name string) {
+	if c.defaulted == nil {
+		return
+	}
+
+	c.defaulted.mu.Lock()
+	c.defaulted.names[name] = true
+	c.defaulted.mu.Unlock()
+}
+
+// splitEnviron splits an entry returned by os.Environ into a name and
+// a value. The search for "=" starts at the second character, since
+// on Windows some variable names start with "=".
+func splitEnviron(entry string) (string, string) {
+	i := strings.Index(entry[1:], "=") + 1
+	if i == 0 {
+		return entry, ""
+	}
+
+	return entry[:i], entry[i+1:]
+}
+
+// fullName returns the name of the variable that c looks up for
+// name.
+func (c *Config) fullName(name string) string {
+	if c.prefixDedup && strings.HasPrefix(name, c.prefix) {
+		return name
+	}
+
+	return c.prefix + name
+}
+
+// trimSecret trims the contents of a file according to the mode
+// configured with WithSecretTrim.
+func (c *Config) trimSecret(content string) string {
+	switch c.secretTrim {
+	case SecretTrimSpace:
+		return strings.TrimSpace(content)
+	case SecretTrimNone:
+		return content
+	default:
+		if strings.HasSuffix(content, "\r\n") {
+			return strings.TrimSuffix(content, "\r\n")
+		}
+		return strings.TrimSuffix(content, "\n")
+	}
+}
+
+// applyValueCase converts val to the case configured with
+// WithValueCase.
+func (c *Config) applyValueCase(val string) string {
+	switch c.valueCase {
+	case ValueCaseLower:
+		return strings.ToLower(val)
+	case ValueCaseUpper:
+		return strings.ToUpper(val)
+	default:
+		return val
+	}
+}
+
+// transformElement applies the function registered with
+// WithSliceElementTransform, if any, to a single list element.
+func (c *Config) transformElement(elem string) string {
+	if c.sliceElement == nil {
+		return elem
+	}
+
+	return c.sliceElement(elem)
+}
+
+// transformElements applies transformElement to each element of
+// elems, modifying it in place.
+func (c *Config) transformElements(elems []string) []string {
+	for i, elem := range elems {
+		elems[i] = c.transformElement(elem)
+	}
+
+	return elems
+}
+
+// dropEmptyElements removes empty and whitespace-only elements from
+// elems if c was created with WithDropEmptyListElements. The result
+// is never nil unless elems is nil.
+func (c *Config) dropEmptyElements(elems []string) []string {
+	if !c.dropEmpty {
+		return elems
+	}
+
+	ret := elems[:0]
+	for _, elem := range elems {
+		if strings.TrimSpace(elem) != "" {
+			ret = append(ret, elem)
+		}
+	}
+
+	return ret
+}
+
+// parseError reports a failure to convert the value of the named
+// variable to the error handler configured with WithErrorHandler, and
+// records that the variable fell back to its default value.
+func (c *Config) parseError(name, val string, err error) {
+	c.recordDefault(name)
+
+	if c.errorHandler != nil {
+		c.errorHandler(name, val, err)
+	}
+}
@@ -0,0 +1,675 @@
+package decouple
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func (t *TestSuite) TestNewWithPrefix() {
+	expected := "This is a test"
+	t.NoError(os.Setenv("APP_TEST_VAR_EXISTS", expected))
+	cfg := New(WithPrefix("APP_"))
+
+	have, exists := cfg.GetString("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestWithEnviron() {
+	expected := 9000
+	cfg := New(
+		WithEnviron(map[string]string{"APP_PORT": "9000"}),
+		WithPrefix("APP_"),
+	)
+
+	have, exists := cfg.GetInt("PORT", 8080)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestWithEnvironIgnoresProcessEnvironment() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "from the environment"))
+	cfg := New(WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetString("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestWithEmptyAsUnsetEnabled() {
+	t.NoError(os.Setenv("TEST_VAR_EMPTY", ""))
+	cfg := New(WithEmptyAsUnset(true))
+
+	have, exists := cfg.GetString("TEST_VAR_EMPTY", "default")
+	t.False(exists)
+	t.Equal(have, "default")
+
+	haveInt, exists := cfg.GetInt("TEST_VAR_EMPTY", 42)
+	t.False(exists)
+	t.Equal(haveInt, 42)
+}
+
+func (t *TestSuite) TestWithEmptyAsUnsetDisabled() {
+	t.NoError(os.Setenv("TEST_VAR_EMPTY", ""))
+	cfg := New(WithEmptyAsUnset(false))
+
+	have, exists := cfg.GetString("TEST_VAR_EMPTY", "default")
+	t.True(exists)
+	t.Equal(have, "")
+}
+
+func (t *TestSuite) TestWithErrorHandlerParseFailure() {
+	var names, values []string
+	var errs []error
+	cfg := New(WithErrorHandler(func(name, value string, err error) {
+		names = append(names, name)
+		values = append(values, value)
+		errs = append(errs, err)
+	}))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "verbose"))
+
+	have, exists := cfg.GetInt("TEST_VAR_EXISTS", 1)
+	t.False(exists)
+	t.Equal(have, 1)
+	t.Equal(names, []string{"TEST_VAR_EXISTS"})
+	t.Equal(values, []string{"verbose"})
+	t.Len(errs, 1)
+	t.Error(errs[0])
+}
+
+func (t *TestSuite) TestWithErrorHandlerNotExists() {
+	var names []string
+	cfg := New(WithErrorHandler(func(name, value string, err error) {
+		names = append(names, name)
+	}))
+
+	_, exists := cfg.GetInt("TEST_VAR_NOT_EXISTS", 1)
+	t.False(exists)
+	t.Empty(names)
+}
+
+func (t *TestSuite) TestWithErrorHandlerValid() {
+	var names []string
+	cfg := New(WithErrorHandler(func(name, value string, err error) {
+		names = append(names, name)
+	}))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "42"))
+
+	_, exists := cfg.GetInt("TEST_VAR_EXISTS", 1)
+	t.True(exists)
+	t.Empty(names)
+}
+
+func (t *TestSuite) TestAliasDeprecatedNewNamePrecedence() {
+	var warnings []string
+	cfg := New(WithEnviron(map[string]string{
+		"OLD_NAME": "old",
+		"NEW_NAME": "new",
+	}))
+	cfg.AliasDeprecated("OLD_NAME", "NEW_NAME")
+	cfg.SetDeprecationHandler(func(oldName, newName string) {
+		warnings = append(warnings, oldName+"->"+newName)
+	})
+
+	have, exists := cfg.GetString("NEW_NAME", "default")
+	t.True(exists)
+	t.Equal(have, "new")
+	t.Empty(warnings)
+}
+
+func (t *TestSuite) TestAliasDeprecatedOldName() {
+	var warnings []string
+	cfg := New(WithEnviron(map[string]string{
+		"OLD_NAME": "old",
+	}))
+	cfg.AliasDeprecated("OLD_NAME", "NEW_NAME")
+	cfg.SetDeprecationHandler(func(oldName, newName string) {
+		warnings = append(warnings, oldName+"->"+newName)
+	})
+
+	have, exists := cfg.GetString("NEW_NAME", "default")
+	t.True(exists)
+	t.Equal(have, "old")
+	t.Equal(warnings, []string{"OLD_NAME->NEW_NAME"})
+}
+
+func (t *TestSuite) TestAliasDeprecatedNotExists() {
+	var warnings []string
+	cfg := New(WithEnviron(map[string]string{}))
+	cfg.AliasDeprecated("OLD_NAME", "NEW_NAME")
+	cfg.SetDeprecationHandler(func(oldName, newName string) {
+		warnings = append(warnings, oldName+"->"+newName)
+	})
+
+	have, exists := cfg.GetString("NEW_NAME", "default")
+	t.False(exists)
+	t.Equal(have, "default")
+	t.Empty(warnings)
+}
+
+func (t *TestSuite) TestDumpAll() {
+	t.NoError(os.Setenv("DUMPTEST_ONE", "1"))
+	t.NoError(os.Setenv("DUMPTEST_TWO", "2"))
+	t.NoError(os.Setenv("OTHER_DUMPTEST_THREE", "3"))
+	cfg := New(WithPrefix("DUMPTEST_"))
+
+	have := cfg.DumpAll()
+	t.Equal(have, map[string]string{"ONE": "1", "TWO": "2"})
+}
+
+func (t *TestSuite) TestDumpAllWithEnviron() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_PORT": "9000",
+		"APP_HOST": "localhost",
+		"PORT":     "8080",
+	}))
+
+	have := cfg.DumpAll()
+	t.Equal(have, map[string]string{"PORT": "9000", "HOST": "localhost"})
+}
+
+func (t *TestSuite) TestBindExists() {
+	cfg := New(WithEnviron(map[string]string{
+		"HOST":  "example.com",
+		"PORT":  "9000",
+		"DEBUG": "true",
+	}))
+
+	var host string
+	var port int
+	var debug bool
+	cfg.BindString(&host, "HOST", "localhost")
+	cfg.BindInt(&port, "PORT", 8080)
+	cfg.BindBool(&debug, "DEBUG", false)
+
+	t.Equal(host, "example.com")
+	t.Equal(port, 9000)
+	t.True(debug)
+}
+
+func (t *TestSuite) TestBindNotExists() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	var host string
+	var port int
+	var debug bool
+	cfg.BindString(&host, "HOST", "localhost")
+	cfg.BindInt(&port, "PORT", 8080)
+	cfg.BindBool(&debug, "DEBUG", true)
+
+	t.Equal(host, "localhost")
+	t.Equal(port, 8080)
+	t.True(debug)
+}
+
+func (t *TestSuite) TestWithPrefixDedupEnabled() {
+	cfg := New(WithPrefix("APP_"), WithPrefixDedup(true), WithEnviron(map[string]string{
+		"APP_PORT": "9000",
+	}))
+
+	have, exists := cfg.GetInt("APP_PORT", 8080)
+	t.True(exists)
+	t.Equal(have, 9000)
+
+	have, exists = cfg.GetInt("PORT", 8080)
+	t.True(exists)
+	t.Equal(have, 9000)
+}
+
+func (t *TestSuite) TestWithPrefixDedupDisabled() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_PORT":     "9000",
+		"APP_APP_PORT": "9001",
+	}))
+
+	have, exists := cfg.GetInt("APP_PORT", 8080)
+	t.True(exists)
+	t.Equal(have, 9001)
+}
+
+func (t *TestSuite) TestWithFileDirFromFile() {
+	dir := t.T().TempDir()
+	t.NoError(os.WriteFile(filepath.Join(dir, "APP_DSN"), []byte("postgres://from-file\n"), 0o600))
+	cfg := New(WithPrefix("APP_"), WithFileDir(dir), WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetString("DSN", "")
+	t.True(exists)
+	t.Equal(have, "postgres://from-file")
+}
+
+func (t *TestSuite) TestWithFileDirEnvPrecedence() {
+	dir := t.T().TempDir()
+	t.NoError(os.WriteFile(filepath.Join(dir, "APP_DSN"), []byte("postgres://from-file\n"), 0o600))
+	cfg := New(WithPrefix("APP_"), WithFileDir(dir), WithEnviron(map[string]string{
+		"APP_DSN": "postgres://from-env",
+	}))
+
+	have, exists := cfg.GetString("DSN", "")
+	t.True(exists)
+	t.Equal(have, "postgres://from-env")
+}
+
+func (t *TestSuite) TestWithFileDirNotExists() {
+	cfg := New(WithFileDir(t.T().TempDir()), WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetString("DSN", "default")
+	t.False(exists)
+	t.Equal(have, "default")
+}
+
+func (t *TestSuite) TestWithFileDirTraversal() {
+	dir := t.T().TempDir()
+	t.NoError(os.WriteFile(filepath.Join(dir, "x"), []byte("secret\n"), 0o600))
+	t.NoError(os.Mkdir(filepath.Join(dir, "config"), 0o700))
+	cfg := New(WithFileDir(filepath.Join(dir, "config")), WithEnviron(map[string]string{}))
+
+	for _, name := range []string{"../x", "..", "sub/x", `..\x`} {
+		have, exists := cfg.GetString(name, "default")
+		t.False(exists, "name %q", name)
+		t.Equal(have, "default", "name %q", name)
+	}
+}
+
+func (t *TestSuite) TestWithSliceElementTransformDefault() {
+	cfg := New(WithEnviron(map[string]string{"HOSTS": "A,B"}))
+
+	have, exists := cfg.GetCSVString("HOSTS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"A", "B"})
+}
+
+func (t *TestSuite) TestWithSliceElementTransformToLower() {
+	cfg := New(
+		WithEnviron(map[string]string{"HOSTS": "A,B", "FEATURES": "x, X,y"}),
+		WithSliceElementTransform(strings.ToLower),
+	)
+
+	have, exists := cfg.GetCSVString("HOSTS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"a", "b"})
+
+	have, exists = cfg.GetStringSliceUnique("FEATURES", nil, ",")
+	t.True(exists)
+	t.Equal(have, []string{"x", "y"})
+}
+
+func (t *TestSuite) TestWithSliceElementTransformNotExists() {
+	expected := []string{"Default"}
+	cfg := New(WithEnviron(map[string]string{}), WithSliceElementTransform(strings.ToLower))
+
+	have, exists := cfg.GetCSVString("HOSTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestWithDropEmptyListElementsDefault() {
+	cfg := New(WithEnviron(map[string]string{"HOSTS": "a,,b"}))
+
+	have, exists := cfg.GetCSVString("HOSTS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"a", "", "b"})
+}
+
+func (t *TestSuite) TestWithDropEmptyListElementsEnabled() {
+	cfg := New(
+		WithEnviron(map[string]string{"HOSTS": "a,, ,b"}),
+		WithDropEmptyListElements(true),
+	)
+
+	have, exists := cfg.GetCSVString("HOSTS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"a", "b"})
+}
+
+func (t *TestSuite) TestWithDropEmptyListElementsAllEmpty() {
+	cfg := New(
+		WithEnviron(map[string]string{"HOSTS": ", ,"}),
+		WithDropEmptyListElements(true),
+	)
+
+	have, exists := cfg.GetCSVString("HOSTS", []string{"default"})
+	t.True(exists)
+	t.Equal(have, []string{})
+}
+
+func (t *TestSuite) TestWithDefaultTracking() {
+	cfg := New(WithDefaultTracking(true), WithEnviron(map[string]string{
+		"HOST": "example.com",
+		"PORT": "eighty",
+	}))
+
+	cfg.GetString("HOST", "localhost")
+	cfg.GetInt("PORT", 8080)
+	cfg.GetBool("DEBUG", false)
+	cfg.GetString("REGION", "us-east-1")
+	cfg.GetBool("DEBUG", false)
+
+	t.Equal(cfg.DefaultedKeys(), []string{"DEBUG", "PORT", "REGION"})
+}
+
+func (t *TestSuite) TestWithDefaultTrackingCompositeGetters() {
+	dir := t.T().TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	t.NoError(os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600))
+	cfg := New(WithDefaultTracking(true), WithDropEmptyListElements(true), WithEnviron(map[string]string{
+		"TOKEN_FILE": tokenFile,
+		"AWS_REGION": "eu-west-1",
+		"SERVERS_0":  "a",
+		"HOST":       "example.com",
+		"URL":        "https://${HOST}:${PORT}/",
+		"LIST_0":     "x",
+		"NAME":       "  ",
+		"SIZE":       "huge",
+		"SIZE_CI":    "huge",
+		"VERSION":    "latest",
+		"ENV":        "qa",
+		"INDEX":      "huge",
+		"CHOICE":     "9",
+		"HOSTS":      "null",
+		"EMPTY":      ",",
+	}))
+	choices := []string{"small", "large"}
+
+	cfg.GetSecret("TOKEN", "")
+	cfg.GetWithSources("TOKEN", "")
+	cfg.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+	cfg.GetIndexedStrings("SERVERS", nil)
+	cfg.GetStringExpanded("URL", "")
+	cfg.GetListFlexible("LIST", nil)
+	t.Empty(cfg.DefaultedKeys())
+
+	cfg.GetSecret("PASSWORD", "")
+	cfg.GetStringOr("ZONE", "AWS_ZONE", "a")
+	cfg.GetIndexedStrings("WORKERS", nil)
+	t.Equal(cfg.DefaultedKeys(), []string{"PASSWORD", "WORKERS", "ZONE"})
+
+	cfg.GetStringNonEmpty("NAME", "default")
+	cfg.GetStringChoices("SIZE", "small", choices)
+	cfg.GetStringChoicesFold("SIZE_CI", "small", choices)
+	cfg.GetStringMatching("VERSION", "v0.0.0", regexp.MustCompile(`v\d+`))
+	cfg.GetStringAliased("ENV", "development", map[string]string{"prod": "production"})
+	cfg.GetChoiceIndex("INDEX", 0, choices)
+	cfg.GetChoiceByNameOrIndex("CHOICE", "small", choices)
+	cfg.GetJSONStringSlice("HOSTS", nil)
+	cfg.GetListFlexible("EMPTY", nil)
+	t.Equal(cfg.DefaultedKeys(), []string{
+		"CHOICE", "EMPTY", "ENV", "HOSTS", "INDEX", "NAME", "PASSWORD",
+		"SIZE", "SIZE_CI", "VERSION", "WORKERS", "ZONE",
+	})
+}
+
+func (t *TestSuite) TestWithDefaultTrackingGenericGetters() {
+	defer Reset()
+	defaultConfig = New(WithDefaultTracking(true), WithEnviron(map[string]string{
+		"LEVEL": "7",
+		"COLOR": "purple",
+	}))
+
+	GetOneOf("LEVEL", 1, strconv.Atoi, []int{1, 2, 3})
+	GetEnumStringer("COLOR", testColorRed, testColors)
+
+	t.Equal(defaultConfig.DefaultedKeys(), []string{"COLOR", "LEVEL"})
+}
+
+func (t *TestSuite) TestWithDefaultTrackingUnreadableFile() {
+	var names []string
+	cfg := New(
+		WithDefaultTracking(true),
+		WithEnviron(map[string]string{
+			"TOKEN_FILE": filepath.Join(t.T().TempDir(), "missing"),
+		}),
+		WithErrorHandler(func(name, value string, err error) {
+			names = append(names, name)
+		}),
+	)
+
+	have, exists := cfg.GetSecret("TOKEN", "default")
+	t.False(exists)
+	t.Equal(have, "default")
+	t.Equal(names, []string{"TOKEN_FILE"})
+	t.Equal(cfg.DefaultedKeys(), []string{"TOKEN"})
+}
+
+func (t *TestSuite) TestWithDefaultTrackingDisabled() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	cfg.GetString("REGION", "us-east-1")
+
+	t.Nil(cfg.DefaultedKeys())
+}
+
+func (t *TestSuite) TestWithValueCommentsEnabled() {
+	cfg := New(WithValueComments(true), WithEnviron(map[string]string{
+		"PORT":  "8080 # default",
+		"DEBUG": "true\t# for now",
+		"TITLE": `"Issue #1" # quoted`,
+		"PASS":  "a#b",
+	}))
+
+	port, exists := cfg.GetInt("PORT", 0)
+	t.True(exists)
+	t.Equal(port, 8080)
+
+	debug, exists := cfg.GetBool("DEBUG", false)
+	t.True(exists)
+	t.True(debug)
+
+	title, exists := cfg.GetString("TITLE", "")
+	t.True(exists)
+	t.Equal(title, `"Issue #1"`)
+
+	pass, exists := cfg.GetString("PASS", "")
+	t.True(exists)
+	t.Equal(pass, "a#b")
+}
+
+func (t *TestSuite) TestWithValueCommentsDisabled() {
+	cfg := New(WithEnviron(map[string]string{
+		"PORT":  "8080 # default",
+		"TITLE": "Issue #1",
+	}))
+
+	_, exists := cfg.GetInt("PORT", 0)
+	t.False(exists)
+
+	title, exists := cfg.GetString("TITLE", "")
+	t.True(exists)
+	t.Equal(title, "Issue #1")
+}
+
+func (t *TestSuite) TestWithValueCase() {
+	env := map[string]string{"REGION": "US-East-1"}
+
+	for mode, expected := range map[string]string{
+		ValueCaseLower: "us-east-1",
+		ValueCaseUpper: "US-EAST-1",
+		ValueCaseNone:  "US-East-1",
+		"":             "US-East-1",
+	} {
+		cfg := New(WithValueCase(mode), WithEnviron(env))
+		have, exists := cfg.GetString("REGION", "")
+		t.True(exists)
+		t.Equal(have, expected, "mode %q", mode)
+	}
+}
+
+func (t *TestSuite) TestWithValueCaseChoices() {
+	env := map[string]string{"REGION": "US-East-1"}
+	choices := []string{"us-east-1", "eu-west-1"}
+
+	have, exists := New(WithEnviron(env)).GetStringChoices("REGION", "eu-west-1", choices)
+	t.True(exists)
+	t.Equal(have, "eu-west-1")
+
+	cfg := New(WithValueCase(ValueCaseLower), WithEnviron(env))
+	have, exists = cfg.GetStringChoices("REGION", "eu-west-1", choices)
+	t.True(exists)
+	t.Equal(have, "us-east-1")
+
+	index, exists := cfg.GetChoiceIndex("REGION", -1, choices)
+	t.True(exists)
+	t.Equal(index, 0)
+}
+
+func (t *TestSuite) TestWithValueCaseNotExists() {
+	cfg := New(WithValueCase(ValueCaseUpper), WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetString("REGION", "us-east-1")
+	t.False(exists)
+	t.Equal(have, "us-east-1")
+}
+
+func (t *TestSuite) TestWithValueCaseStringOr() {
+	cfg := New(WithValueCase(ValueCaseLower), WithEnviron(map[string]string{
+		"REGION":     "US-East-1",
+		"AWS_REGION": "EU-West-1",
+	}))
+
+	have, exists := cfg.GetStringOr("REGION", "AWS_REGION", "")
+	t.True(exists)
+	t.Equal(have, "us-east-1")
+
+	have, exists = cfg.GetStringOr("ZONE", "AWS_REGION", "")
+	t.True(exists)
+	t.Equal(have, "eu-west-1")
+}
+
+func (t *TestSuite) TestWithValueCaseUnknownMode() {
+	t.Panics(func() { WithValueCase("Lower") })
+	t.Panics(func() { WithValueCase("title") })
+}
+
+type lookupObservation struct {
+	name, resolvedName, value string
+	fromEnv                   bool
+}
+
+func (t *TestSuite) TestWithObserver() {
+	var observations []lookupObservation
+	cfg := New(
+		WithPrefix("APP_"),
+		WithEnviron(map[string]string{
+			"APP_PORT":  "9000",
+			"APP_DEBUG": "yes please",
+		}),
+		WithObserver(func(name, resolvedName, value string, fromEnv bool) {
+			observations = append(observations, lookupObservation{name, resolvedName, value, fromEnv})
+		}),
+	)
+
+	cfg.GetInt("PORT", 8080)
+	cfg.GetIntInRange("WORKERS", 4, 1, 16)
+	cfg.GetBool("DEBUG", false)
+
+	t.Equal(observations, []lookupObservation{
+		{"PORT", "APP_PORT", "9000", true},
+		{"WORKERS", "APP_WORKERS", "", false},
+		{"DEBUG", "APP_DEBUG", "yes please", true},
+	})
+}
+
+func (t *TestSuite) TestWithObserverAlias() {
+	var observations []lookupObservation
+	cfg := New(
+		WithEnviron(map[string]string{
+			"OLD_NAME": "old",
+		}),
+		WithObserver(func(name, resolvedName, value string, fromEnv bool) {
+			observations = append(observations, lookupObservation{name, resolvedName, value, fromEnv})
+		}),
+	)
+	cfg.AliasDeprecated("OLD_NAME", "NEW_NAME")
+
+	cfg.GetString("NEW_NAME", "")
+
+	t.Equal(observations, []lookupObservation{
+		{"NEW_NAME", "OLD_NAME", "old", true},
+	})
+}
+
+func (t *TestSuite) TestWithObserverCompositeGetters() {
+	dir := t.T().TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	t.NoError(os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600))
+
+	var observations []lookupObservation
+	cfg := New(
+		WithEnviron(map[string]string{
+			"TOKEN_FILE":  tokenFile,
+			"AWS_REGION":  "eu-west-1",
+			"SERVERS_0":   "a",
+			"SERVERS_1":   "b",
+			"HOST":        "example.com",
+			"URL":         "https://${HOST}/",
+			"DSN":         `{{env "HOST"}}:5432`,
+			"LIST_0":      "x",
+			"LIST_INLINE": "y,z",
+		}),
+		WithObserver(func(name, resolvedName, value string, fromEnv bool) {
+			observations = append(observations, lookupObservation{name, resolvedName, value, fromEnv})
+		}),
+	)
+
+	cfg.GetSecret("TOKEN", "")
+	cfg.GetSecret("PASSWORD", "")
+	cfg.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+	cfg.GetStringOr("ZONE", "AWS_ZONE", "a")
+	cfg.GetIndexedStrings("SERVERS", nil)
+	cfg.GetStringExpanded("URL", "")
+	cfg.GetStringTemplate("DSN", "")
+	cfg.GetListFlexible("LIST", nil)
+	cfg.GetListFlexible("LIST_INLINE", nil)
+
+	t.Equal(observations, []lookupObservation{
+		{"TOKEN", "TOKEN_FILE", "s3cret", true},
+		{"PASSWORD", "PASSWORD", "", false},
+		{"REGION", "AWS_REGION", "eu-west-1", true},
+		{"ZONE", "ZONE", "", false},
+		{"SERVERS", "SERVERS_0", "a,b", true},
+		{"URL", "URL", "https://${HOST}/", true},
+		{"DSN", "DSN", `{{env "HOST"}}:5432`, true},
+		{"LIST", "LIST_0", "x", true},
+		{"LIST_INLINE", "LIST_INLINE", "y,z", true},
+	})
+}
+
+func (t *TestSuite) TestWithValues() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "from the environment"))
+
+	WithValues(map[string]string{"TEST_VAR_SCOPED": "scoped"}, func() {
+		have, exists := GetString("TEST_VAR_SCOPED", "")
+		t.True(exists)
+		t.Equal(have, "scoped")
+
+		_, exists = GetString("TEST_VAR_EXISTS", "")
+		t.False(exists)
+	})
+
+	_, exists := GetString("TEST_VAR_SCOPED", "")
+	t.False(exists)
+	have, exists := GetString("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, "from the environment")
+}
+
+func (t *TestSuite) TestWithValuesPanic() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "from the environment"))
+
+	func() {
+		defer func() {
+			t.Equal(recover(), "oops")
+		}()
+
+		WithValues(map[string]string{}, func() {
+			panic("oops")
+		})
+	}()
+
+	have, exists := GetString("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, "from the environment")
+}
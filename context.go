@@ -0,0 +1,73 @@
+package decouple
+
+import (
+	"context"
+)
+
+type prefixKey struct{}
+
+// ContextWithPrefix returns a copy of ctx that carries prefix. The
+// *Ctx functions (GetStringCtx, GetIntCtx, etc.) use this prefix in
+// place of the one configured with SetPrefix, which makes it possible
+// to use different prefixes in different goroutines without modifying
+// shared state.
+//
+// Example:
+//
+//	ctx = decouple.ContextWithPrefix(ctx, "TENANT1_")
+//	dsn, _ := decouple.GetStringCtx(ctx, "DSN", "")
+func ContextWithPrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, prefixKey{}, prefix)
+}
+
+// withContext returns a Config that uses the prefix carried by ctx,
+// if any, or c itself otherwise.
+func (c *Config) withContext(ctx context.Context) *Config {
+	prefix, ok := ctx.Value(prefixKey{}).(string)
+	if !ok {
+		return c
+	}
+
+	ctxConfig := *c
+	ctxConfig.prefix = prefix
+	return &ctxConfig
+}
+
+// GetStringCtx is like GetString, but uses the prefix carried by ctx
+// (see ContextWithPrefix). If ctx does not carry a prefix, the prefix
+// configured with SetPrefix is used.
+func GetStringCtx(ctx context.Context, name, defval string) (string, bool) {
+	return defaultConfig.GetStringCtx(ctx, name, defval)
+}
+
+// GetStringCtx is like the package-level GetStringCtx, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringCtx(ctx context.Context, name, defval string) (string, bool) {
+	return c.withContext(ctx).GetString(name, defval)
+}
+
+// GetIntCtx is like GetInt, but uses the prefix carried by ctx (see
+// ContextWithPrefix). If ctx does not carry a prefix, the prefix
+// configured with SetPrefix is used.
+func GetIntCtx(ctx context.Context, name string, defval int) (int, bool) {
+	return defaultConfig.GetIntCtx(ctx, name, defval)
+}
+
+// GetIntCtx is like the package-level GetIntCtx, but looks up
+// variables using the settings in c.
+func (c *Config) GetIntCtx(ctx context.Context, name string, defval int) (int, bool) {
+	return c.withContext(ctx).GetInt(name, defval)
+}
+
+// GetBoolCtx is like GetBool, but uses the prefix carried by ctx (see
+// ContextWithPrefix). If ctx does not carry a prefix, the prefix
+// configured with SetPrefix is used.
+func GetBoolCtx(ctx context.Context, name string, defval bool) (bool, bool) {
+	return defaultConfig.GetBoolCtx(ctx, name, defval)
+}
+
+// GetBoolCtx is like the package-level GetBoolCtx, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolCtx(ctx context.Context, name string, defval bool) (bool, bool) {
+	return c.withContext(ctx).GetBool(name, defval)
+}
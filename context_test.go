@@ -0,0 +1,49 @@
+package decouple
+
+import (
+	"context"
+	"os"
+)
+
+func (t *TestSuite) TestGetStringCtxPrefix() {
+	t.NoError(os.Setenv("TENANT1_DSN", "postgres://tenant1"))
+	t.NoError(os.Setenv("TENANT2_DSN", "postgres://tenant2"))
+	ctx1 := ContextWithPrefix(context.Background(), "TENANT1_")
+	ctx2 := ContextWithPrefix(context.Background(), "TENANT2_")
+
+	have, exists := GetStringCtx(ctx1, "DSN", "")
+	t.True(exists)
+	t.Equal(have, "postgres://tenant1")
+
+	have, exists = GetStringCtx(ctx2, "DSN", "")
+	t.True(exists)
+	t.Equal(have, "postgres://tenant2")
+}
+
+func (t *TestSuite) TestGetStringCtxDefaultPrefix() {
+	defer SetPrefix("")
+	SetPrefix("TENANT1_")
+	t.NoError(os.Setenv("TENANT1_DSN", "postgres://tenant1"))
+
+	have, exists := GetStringCtx(context.Background(), "DSN", "")
+	t.True(exists)
+	t.Equal(have, "postgres://tenant1")
+}
+
+func (t *TestSuite) TestGetIntCtxPrefix() {
+	t.NoError(os.Setenv("TENANT1_WORKERS", "4"))
+	ctx := ContextWithPrefix(context.Background(), "TENANT1_")
+
+	have, exists := GetIntCtx(ctx, "WORKERS", 1)
+	t.True(exists)
+	t.Equal(have, 4)
+}
+
+func (t *TestSuite) TestGetBoolCtxPrefix() {
+	t.NoError(os.Setenv("TENANT1_DEBUG", "true"))
+	ctx := ContextWithPrefix(context.Background(), "TENANT1_")
+
+	have, exists := GetBoolCtx(ctx, "DEBUG", false)
+	t.True(exists)
+	t.True(have)
+}
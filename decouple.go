@@ -18,16 +18,28 @@ package decouple
 
 import (
 	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"image/color"
+	"math"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/joho/godotenv"
 )
 
-var nameprefix string
-
 // SetPrefix sets a prefix that will be applied when looking for
 // variables. If you call:
 //
@@ -36,14 +48,107 @@ var nameprefix string
 //
 // Then decouple will look for a variable named "FOO_CONFIG".
 func SetPrefix(prefix string) {
-	nameprefix = prefix
+	defaultConfig.SetPrefix(prefix)
+}
+
+// prefixFromProgramName derives a variable prefix from the path to a
+// program, e.g. "/usr/local/bin/my-app" becomes "MY_APP_".
+func prefixFromProgramName(path string) string {
+	name := strings.ToUpper(filepath.Base(path))
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	return name + "_"
+}
+
+// SetPrefixFromProgramName sets the prefix to the name of the running
+// program (os.Args[0]), converted to upper case, with any character
+// that is not a letter, digit or underscore replaced by an
+// underscore, and followed by an underscore. For example, a program
+// named "my-app" will look for variables named "MY_APP_<name>".
+func SetPrefixFromProgramName() {
+	SetPrefix(prefixFromProgramName(os.Args[0]))
 }
 
 // LookupEnv is a proxy for os.LookupEnv that applies the prefix
-// configured with SetPrefix.
+// configured with SetPrefix. It uses the default Config; see
+// Config.LookupEnv.
 func LookupEnv(name string) (string, bool) {
-	name = fmt.Sprintf("%s%s", nameprefix, name)
-	return os.LookupEnv(name)
+	return defaultConfig.LookupEnv(name)
+}
+
+// AliasDeprecated registers oldName as a deprecated name for newName
+// in the default Config. When newName is not set, the Get* functions
+// fall back to oldName; if the value is found there, the handler
+// registered with SetDeprecationHandler is called.
+//
+// Example:
+//
+//	decouple.SetDeprecationHandler(func(oldName, newName string) {
+//		log.Printf("%s is deprecated; use %s instead", oldName, newName)
+//	})
+//	decouple.AliasDeprecated("DB_HOST", "DATABASE_HOST")
+//	host, _ := decouple.GetString("DATABASE_HOST", "localhost")
+func AliasDeprecated(oldName, newName string) {
+	defaultConfig.AliasDeprecated(oldName, newName)
+}
+
+// SetDeprecationHandler registers a function that is called whenever
+// a variable is resolved using a deprecated name registered with
+// AliasDeprecated.
+func SetDeprecationHandler(fn func(oldName, newName string)) {
+	defaultConfig.SetDeprecationHandler(fn)
+}
+
+// Reset restores the default Config to its initial state, discarding
+// the prefix, deprecated aliases, handlers and any other settings
+// made using the package-level functions, along with any converters
+// registered with RegisterFieldConverter and the file origins recorded
+// for Source by Load and Overload. It is intended for use in
+// tests, to keep settings made by one test from leaking into others.
+//
+// Example:
+//
+//	func TestSomething(t *testing.T) {
+//		defer decouple.Reset()
+//		decouple.SetPrefix("APP_")
+//		...
+//	}
+func Reset() {
+	defaultConfig = New()
+	resetFieldConverters()
+
+	loadOriginsMu.Lock()
+	loadOrigins = make(map[string]loadOrigin)
+	loadOriginsMu.Unlock()
+}
+
+// WithValues calls fn with the default Config looking up variables
+// exclusively in env, as if it had been created using WithEnviron.
+// The previous lookup source is restored when fn returns, even if fn
+// panics. This lets tests run without modifying the process
+// environment.
+//
+// WithValues modifies the default Config, so it must not be used
+// concurrently with other code that uses the package-level functions.
+//
+// Example:
+//
+//	decouple.WithValues(map[string]string{"PORT": "9000"}, func() {
+//		port, _ := decouple.GetInt("PORT", 8080)
+//	})
+func WithValues(env map[string]string, fn func()) {
+	saved := defaultConfig.environ
+	defer func() {
+		defaultConfig.environ = saved
+	}()
+
+	defaultConfig.environ = env
+	fn()
 }
 
 // GetString returns the value of an environment variable as a string.
@@ -57,150 +162,3763 @@ func LookupEnv(name string) (string, bool) {
 //	os.Setenv("CONFIG_PATH", "/etc/sharedconfig.yaml")
 //	configpath, _ := decouple.GetString("CONFIG_PATH", "/home/.config/myconfig.yaml")
 func GetString(name, defval string) (string, bool) {
-	val, exists := LookupEnv(name)
+	return defaultConfig.GetString(name, defval)
+}
+
+// GetString is like the package-level GetString, but looks up
+// variables using the settings in c.
+func (c *Config) GetString(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
 	if !exists {
 		return defval, false
 	}
 
-	return val, true
+	return c.applyValueCase(val), true
 }
 
-// GetStringChoices returns the value of an environment as a string if
-// it is a valid choice. Otherwise, returns a default value.
+// GetStringNonEmpty is like GetString, but treats a variable that is
+// empty or contains only whitespace as if it did not exist.
 //
-// If the named variable exists and is a valid choice, return the
-// tuple (value, true). If the named variable exists but is not a valid
-// choice, return (defval, true). If the named variable does not
-// exist, return (defval, false).
+// If the named variable exists and contains non-whitespace
+// characters, return (value, true). Otherwise, return (defval,
+// false).
 //
 // Example:
 //
-//	os.SetEnv("WIDGET_SIZE", "small")
-//	widget_size := GetStringChoices("WIDGET_SIZE", "small", []string{"small", "medium", "large"})
-func GetStringChoices(name, defval string, choices []string) (string, bool) {
-	val, exists := GetString(name, defval)
+//	os.Setenv("CLUSTER_NAME", "")
+//	clusterName, _ := decouple.GetStringNonEmpty("CLUSTER_NAME", "default")
+func GetStringNonEmpty(name, defval string) (string, bool) {
+	return defaultConfig.GetStringNonEmpty(name, defval)
+}
 
-	for _, choice := range choices {
-		if val == choice {
-			return val, exists
-		}
+// GetStringNonEmpty is like the package-level GetStringNonEmpty, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringNonEmpty(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
 	}
 
-	return defval, exists
+	if strings.TrimSpace(val) == "" {
+		c.recordDefault(name)
+		return defval, false
+	}
+
+	return val, true
 }
 
-// GetInt returns the value of an environment variable as an int.
+// GetStringMaxLen is like GetString, but rejects values longer than
+// maxLen characters (counted as runes, not bytes).
 //
-// If the named variable exists, attempt to convert it to an integer.
-// If the conversion is successful, return (value, true). If the
-// conversion fails or if the named variable does not exist, return
-// (defval, false).
+// If the named variable exists and its value is no longer than
+// maxLen, return (value, true). If the value is too long or if the
+// named variable does not exist, return (defval, false).
 //
 // Example:
 //
-//	os.Setenv("WIDGET_COUNT", 2)
-//	widgetCount, _ := decouple.GetInt("WIDGET_COUNT", 10)
-func GetInt(name string, defval int) (int, bool) {
-	val, exists := LookupEnv(name)
+//	os.Setenv("DEPLOYMENT_LABEL", "blue")
+//	label, _ := decouple.GetStringMaxLen("DEPLOYMENT_LABEL", "default", 64)
+func GetStringMaxLen(name, defval string, maxLen int) (string, bool) {
+	return defaultConfig.GetStringMaxLen(name, defval, maxLen)
+}
+
+// GetStringMaxLen is like the package-level GetStringMaxLen, but looks
+// up variables using the settings in c.
+func (c *Config) GetStringMaxLen(name, defval string, maxLen int) (string, bool) {
+	val, exists := c.LookupEnv(name)
 	if !exists {
 		return defval, false
 	}
 
-	ret, err := strconv.ParseInt(val, 0, 0)
-	if err != nil {
+	if n := utf8.RuneCountInString(val); n > maxLen {
+		c.parseError(name, val, fmt.Errorf("value is %d characters long, maximum is %d", n, maxLen))
 		return defval, false
 	}
 
-	return int(ret), true
+	return val, true
 }
 
-// GetIntInRange returns the value of environment variable as an int,
-// clamped to an explicit range.
+// GetStringRedacted is like GetString, but also returns a version of
+// the value that is safe to log: "***" if the value is non-empty, or
+// the empty string otherwise.
 //
-// If the named variable exists, attempt to convert it to an integer.
-// If the conversion is successful and the value falls within the
-// given range, return (value, true). If value > maxval, return
-// (maxval, true). If value < minval, return (minval, true). If the
-// conversion fails or if the named variable does not exist, return
-// (defval, false).
+// Example:
+//
+//	token, display, _ := decouple.GetStringRedacted("API_TOKEN", "")
+//	log.Printf("API_TOKEN=%s", display)
+func GetStringRedacted(name, defval string) (value string, display string, exists bool) {
+	return defaultConfig.GetStringRedacted(name, defval)
+}
+
+// GetStringRedacted is like the package-level GetStringRedacted, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringRedacted(name, defval string) (value string, display string, exists bool) {
+	value, exists = c.GetString(name, defval)
+	if value != "" {
+		display = "***"
+	}
+
+	return value, display, exists
+}
+
+// GetStringOr returns the value of an environment variable as a
+// string, falling back to a second variable if the first one does
+// not exist.
+//
+// If the named variable exists, return (value, true). Otherwise, if
+// the variable named by fallbackName exists, return (fallback value,
+// true). If neither variable exists, return (defval, false). The
+// prefix configured with SetPrefix is applied to both name and
+// fallbackName.
 //
 // Example:
 //
-//	os.Setenv("LOG_LEVEL", 2)
-//	logLevel, _ := decouple.GetIntInRange("LOG_LEVEL", 1, -1, 5)
-func GetIntInRange(name string, defval, minval, maxval int) (int, bool) {
-	ret, exists := GetInt(name, defval)
+//	os.Setenv("AWS_REGION", "eu-west-1")
+//	region, _ := decouple.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+func GetStringOr(name, fallbackName, defval string) (string, bool) {
+	return defaultConfig.GetStringOr(name, fallbackName, defval)
+}
 
-	switch {
-	case ret < minval:
-		ret = minval
-	case ret > maxval:
-		ret = maxval
+// GetStringOr is like the package-level GetStringOr, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringOr(name, fallbackName, defval string) (string, bool) {
+	if val, resolvedName, exists := c.lookup(name); exists {
+		c.observe(name, resolvedName, val, true)
+		return c.applyValueCase(val), true
+	}
+
+	val, resolvedName, exists := c.lookup(fallbackName)
+	if !exists {
+		c.observe(name, c.fullName(name), "", false)
+		c.recordDefault(name)
+		return defval, false
 	}
 
-	return int(ret), exists
+	c.observe(name, resolvedName, val, true)
+	return c.applyValueCase(val), true
 }
 
-// GetBool returns the value of an environment variable as a
-// boolean.
+// Sources reported by GetWithSources.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// GetAllStrings looks up each of names as GetString would, using the
+// entry in defaults (or the empty string) as the default value. It
+// returns a map of values and a map reporting whether each variable
+// exists, both keyed by name.
 //
-// If the named variable exists, attempt to convert it to a bool. If
-// the conversion is successful, return (value, true). If the
-// conversion fails or if the named variable does not exist, return
+// Example:
+//
+//	values, exists := decouple.GetAllStrings(
+//		[]string{"DB_HOST", "DB_USER"},
+//		map[string]string{"DB_HOST": "localhost"},
+//	)
+func GetAllStrings(names []string, defaults map[string]string) (map[string]string, map[string]bool) {
+	return defaultConfig.GetAllStrings(names, defaults)
+}
+
+// GetAllStrings is like the package-level GetAllStrings, but looks up
+// variables using the settings in c.
+func (c *Config) GetAllStrings(names []string, defaults map[string]string) (map[string]string, map[string]bool) {
+	values := make(map[string]string, len(names))
+	exists := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		values[name], exists[name] = c.GetString(name, defaults[name])
+	}
+
+	return values, exists
+}
+
+// GetWithSources returns the value of an environment variable as a
+// string, falling back to the contents of a file, and reports where
+// the value came from.
+//
+// If the named variable exists, return (value, SourceEnv, true).
+// Otherwise, if the variable name+"_FILE" exists and names a readable
+// file, return (contents, SourceFile, true); the contents are trimmed
+// as configured with WithSecretTrim. Otherwise, return (defval,
+// SourceDefault, false).
+//
+// Example:
+//
+//	os.Setenv("DB_PASSWORD_FILE", "/run/secrets/db_password")
+//	password, source, _ := decouple.GetWithSources("DB_PASSWORD", "")
+func GetWithSources(name, defval string) (value string, source string, exists bool) {
+	return defaultConfig.GetWithSources(name, defval)
+}
+
+// GetWithSources is like the package-level GetWithSources, but looks
+// up variables using the settings in c.
+func (c *Config) GetWithSources(name, defval string) (value string, source string, exists bool) {
+	if val, resolvedName, exists := c.lookup(name); exists {
+		c.observe(name, resolvedName, val, true)
+		return val, SourceEnv, true
+	}
+
+	if path, resolvedName, exists := c.lookup(name + "_FILE"); exists {
+		content, err := os.ReadFile(path)
+		if err == nil {
+			val := c.trimSecret(string(content))
+			c.observe(name, resolvedName, val, true)
+			return val, SourceFile, true
+		}
+
+		if c.errorHandler != nil {
+			c.errorHandler(name+"_FILE", path, err)
+		}
+	}
+
+	c.observe(name, c.fullName(name), "", false)
+	c.recordDefault(name)
+	return defval, SourceDefault, false
+}
+
+// GetSecret returns the value of a secret, such as a password or an
+// API token, read either from the named environment variable or from
+// the file named by the variable name+"_FILE", as described for
+// GetWithSources. By default a single trailing newline is removed
+// from the contents of the file; see WithSecretTrim.
+//
+// If the secret is found, return (value, true). Otherwise, return
 // (defval, false).
 //
 // Example:
 //
-//	os.Setenv("DEBUG_MODE", "true")
-// 	debugMode, _ := decouple.GetBool("DEBUG_MODE")
-func GetBool(name string, defval bool) (bool, bool) {
-	val, exists := LookupEnv(name)
+//	os.Setenv("API_TOKEN_FILE", "/run/secrets/api_token")
+//	token, _ := decouple.GetSecret("API_TOKEN", "")
+func GetSecret(name, defval string) (string, bool) {
+	return defaultConfig.GetSecret(name, defval)
+}
+
+// GetSecret is like the package-level GetSecret, but looks up
+// variables using the settings in c.
+func (c *Config) GetSecret(name, defval string) (string, bool) {
+	val, _, exists := c.GetWithSources(name, defval)
+	return val, exists
+}
+
+// ErrNotSet is returned (possibly wrapped) by functions that require
+// a variable to exist when it does not.
+var ErrNotSet = errors.New("variable is not set")
+
+// GetStringRequired returns the value of an environment variable as
+// a string, or an error if the variable does not exist.
+//
+// If the named variable exists, return (value, nil). Otherwise,
+// return an error that wraps ErrNotSet and includes the name of the
+// variable (with the prefix applied).
+//
+// Example:
+//
+//	dbURL, err := decouple.GetStringRequired("DB_URL")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func GetStringRequired(name string) (string, error) {
+	return defaultConfig.GetStringRequired(name)
+}
+
+// GetStringRequired is like the package-level GetStringRequired, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringRequired(name string) (string, error) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return "", fmt.Errorf("%s: %w", c.fullName(name), ErrNotSet)
+	}
+
+	return val, nil
+}
+
+// GetStringChecked returns the value of an environment variable as a
+// string after checking it with validate.
+//
+// If the named variable exists and validate returns nil, return
+// (value, nil). If validate returns an error, return defval and that
+// error, wrapped with the name of the variable. If the named variable
+// does not exist, return defval and an error that wraps ErrNotSet.
+//
+// Example:
+//
+//	region, err := decouple.GetStringChecked("REGION", "us-east-1", func(s string) error {
+//		if !strings.Contains(s, "-") {
+//			return errors.New("must be of the form <area>-<location>-<n>")
+//		}
+//		return nil
+//	})
+func GetStringChecked(name, defval string, validate func(string) error) (string, error) {
+	return defaultConfig.GetStringChecked(name, defval, validate)
+}
+
+// GetStringChecked is like the package-level GetStringChecked, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringChecked(name, defval string, validate func(string) error) (string, error) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, fmt.Errorf("%s: %w", c.fullName(name), ErrNotSet)
+	}
+
+	if err := validate(val); err != nil {
+		c.parseError(name, val, err)
+		return defval, fmt.Errorf("%s: %w", c.fullName(name), err)
+	}
+
+	return val, nil
+}
+
+// GetStringMustf returns the value of an environment variable as a
+// string. If the variable does not exist, GetStringMustf panics with
+// the message produced by calling fmt.Sprintf with format and args.
+//
+// Example:
+//
+//	dbURL := decouple.GetStringMustf("DB_URL", "set DB_URL to your Postgres DSN")
+func GetStringMustf(name, format string, args ...interface{}) string {
+	return defaultConfig.GetStringMustf(name, format, args...)
+}
+
+// GetStringMustf is like the package-level GetStringMustf, but looks
+// up variables using the settings in c.
+func (c *Config) GetStringMustf(name, format string, args ...interface{}) string {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		panic(fmt.Sprintf(format, args...))
+	}
+
+	return val
+}
+
+// GetStringExpanded returns the value of an environment variable as a
+// string, with references to other variables expanded.
+//
+// References of the form ${NAME} or $NAME are resolved using the same
+// lookup as the Get* functions, so the prefix configured with
+// SetPrefix is applied to NAME. References to variables that do not
+// exist expand to the empty string. Use "$$" for a literal "$".
+//
+// If the named variable exists, return (expanded value, true). If the
+// named variable does not exist, return (defval, false); defval is
+// not expanded.
+//
+// Example:
+//
+//	os.Setenv("HOST", "example.com")
+//	os.Setenv("PORT", "8443")
+//	os.Setenv("URL", "https://${HOST}:${PORT}")
+//	url, _ := decouple.GetStringExpanded("URL", "")
+func GetStringExpanded(name, defval string) (string, bool) {
+	return defaultConfig.GetStringExpanded(name, defval)
+}
+
+// GetStringExpanded is like the package-level GetStringExpanded, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringExpanded(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
 	if !exists {
 		return defval, false
 	}
 
-	ret, err := strconv.ParseBool(val)
+	return os.Expand(val, func(ref string) string {
+		if ref == "$" {
+			return "$"
+		}
+
+		refval, _, _ := c.lookup(ref)
+		return refval
+	}), true
+}
+
+// GetStringTemplate returns the value of an environment variable
+// rendered as a text/template.
+//
+// The template is executed with a map of all variables that start
+// with the prefix configured with SetPrefix, keyed by name with the
+// prefix removed (see DumpAll), so that {{.HOST}} refers to the
+// variable HOST. The template can also call the function "env" to
+// look up any variable, e.g. {{env "HOST"}}, which returns the empty
+// string if the variable does not exist.
+//
+// If the named variable exists and the template can be parsed and
+// executed, return (rendered value, true). If the template cannot be
+// parsed or executed, including when it refers to a key that does
+// not exist using the {{.NAME}} form, or if the named variable does
+// not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("HOST", "db.example.com")
+//	os.Setenv("PORT", "5432")
+//	os.Setenv("DSN", "{{.HOST}}:{{.PORT}}")
+//	dsn, _ := decouple.GetStringTemplate("DSN", "localhost:5432")
+func GetStringTemplate(name, defval string) (string, bool) {
+	return defaultConfig.GetStringTemplate(name, defval)
+}
+
+// GetStringTemplate is like the package-level GetStringTemplate, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringTemplate(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	funcs := template.FuncMap{
+		"env": func(ref string) string {
+			refval, _, _ := c.lookup(ref)
+			return refval
+		},
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(val)
 	if err != nil {
+		c.parseError(name, val, err)
 		return defval, false
 	}
 
-	return ret, true
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, c.DumpAll()); err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return buf.String(), true
 }
 
-// GetCSVString parses an environment variable as a single row in a
-// CSV document and returns a list of strings.
+// unescape interprets Go escape sequences such as \n, \t, \\ and \xNN
+// in val. Unlike strconv.Unquote, val is not surrounded by quotes and
+// may contain unescaped double quotes.
+func unescape(val string) (string, error) {
+	var buf strings.Builder
+
+	for len(val) > 0 {
+		if val[0] == '"' {
+			buf.WriteByte('"')
+			val = val[1:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(val, '"')
+		if err != nil {
+			return "", err
+		}
+
+		if r < utf8.RuneSelf || !multibyte {
+			buf.WriteByte(byte(r))
+		} else {
+			buf.WriteRune(r)
+		}
+
+		val = tail
+	}
+
+	return buf.String(), nil
+}
+
+// GetStringUnescaped returns the value of an environment variable as
+// a string, with Go escape sequences such as \n, \t, \\, \xNN and
+// \uNNNN replaced by the characters they represent. This is useful
+// for values such as private keys that are stored in a .env file
+// with literal "\n" separators.
+//
+// If the named variable exists and contains only valid escape
+// sequences, return (unescaped value, true). If the value contains
+// an invalid escape sequence or if the named variable does not exist,
+// return (defval, false).
 //
 // Example:
 //
-//	os.Setenv("LIST_OF_NAMES", "alice,bob,carol")
-//	names, _ := decouple.GetCSVString("LIST_OF_NAMES", []string{})
-func GetCSVString(name string, defval []string) ([]string, bool) {
-	val, exists := GetString(name, "")
+//	os.Setenv("FIELD_SEP", `\t`)
+//	fieldSep, _ := decouple.GetStringUnescaped("FIELD_SEP", ",")
+func GetStringUnescaped(name, defval string) (string, bool) {
+	return defaultConfig.GetStringUnescaped(name, defval)
+}
+
+// GetStringUnescaped is like the package-level GetStringUnescaped, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringUnescaped(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
 	if !exists {
 		return defval, false
 	}
 
-	r := strings.NewReader(val)
-	csvr := csv.NewReader(r)
-	rec, err := csvr.Read()
+	ret, err := unescape(val)
 	if err != nil {
+		c.parseError(name, val, err)
 		return defval, false
 	}
 
-	return rec, true
+	return ret, true
 }
 
-// Load is a proxy for godotenv.Load. It will load environment
-// variables from the named files, or from '.env' if no filenames are
-// provided.
+// GetStringChoices returns the value of an environment as a string if
+// it is a valid choice. Otherwise, returns a default value.
 //
-// Load variables from '.env':
+// If the named variable exists and is a valid choice, return the
+// tuple (value, true). If the named variable exists but is not a valid
+// choice, return (defval, true). If the named variable does not
+// exist, return (defval, false).
 //
-//	decouple.Load()
+// Example:
 //
-// Load variables from 'production.env':
+//	os.SetEnv("WIDGET_SIZE", "small")
+//	widget_size := GetStringChoices("WIDGET_SIZE", "small", []string{"small", "medium", "large"})
+func GetStringChoices(name, defval string, choices []string) (string, bool) {
+	return defaultConfig.GetStringChoices(name, defval, choices)
+}
+
+// GetStringChoices is like the package-level GetStringChoices, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringChoices(name, defval string, choices []string) (string, bool) {
+	val, exists := c.GetString(name, defval)
+
+	for _, choice := range choices {
+		if val == choice {
+			return val, exists
+		}
+	}
+
+	c.recordDefault(name)
+	return defval, exists
+}
+
+// GetStringChoicesOrFirst is like GetStringChoices, but uses the
+// first element of choices as the default value. It panics if choices
+// is empty, since there is then no default to return.
 //
-//	decouple.Load("production.env")
-func Load(filenames ...string) error {
-	return godotenv.Load(filenames...)
+// Example:
+//
+//	os.Setenv("WIDGET_SIZE", "large")
+//	size, _ := decouple.GetStringChoicesOrFirst("WIDGET_SIZE", []string{"small", "medium", "large"})
+func GetStringChoicesOrFirst(name string, choices []string) (string, bool) {
+	return defaultConfig.GetStringChoicesOrFirst(name, choices)
+}
+
+// GetStringChoicesOrFirst is like the package-level
+// GetStringChoicesOrFirst, but looks up variables using the settings
+// in c.
+func (c *Config) GetStringChoicesOrFirst(name string, choices []string) (string, bool) {
+	if len(choices) == 0 {
+		panic(fmt.Sprintf("decouple: no choices given for %s", c.fullName(name)))
+	}
+
+	return c.GetStringChoices(name, choices[0], choices)
+}
+
+// GetStringChoicesStrict is like GetStringChoices, but also checks
+// that defval is itself one of choices, so that an invalid value
+// cannot fall back to an equally invalid default. If it is not, the
+// error is non-nil; the value and exists flag are still those that
+// GetStringChoices would return.
+//
+// Example:
+//
+//	size, _, err := decouple.GetStringChoicesStrict("WIDGET_SIZE", "small", []string{"small", "medium", "large"})
+//	if err != nil {
+//		panic(err)
+//	}
+func GetStringChoicesStrict(name, defval string, choices []string) (string, bool, error) {
+	return defaultConfig.GetStringChoicesStrict(name, defval, choices)
+}
+
+// GetStringChoicesStrict is like the package-level
+// GetStringChoicesStrict, but looks up variables using the settings in
+// c.
+func (c *Config) GetStringChoicesStrict(name, defval string, choices []string) (string, bool, error) {
+	val, exists := c.GetStringChoices(name, defval, choices)
+
+	for _, choice := range choices {
+		if defval == choice {
+			return val, exists, nil
+		}
+	}
+
+	return val, exists, fmt.Errorf("%s: default %q is not one of %q", c.fullName(name), defval, choices)
+}
+
+// GetStringMatching returns the value of an environment variable as a
+// string if it matches a regular expression. Otherwise, returns a
+// default value.
+//
+// The entire value must match pattern; it is not necessary to anchor
+// the pattern with "^" and "$". If the named variable exists and
+// matches, return (value, true). If the named variable exists but
+// does not match, return (defval, true). If the named variable does
+// not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("VERSION", "v1.2.3")
+//	version, _ := decouple.GetStringMatching("VERSION", "v0.0.0", regexp.MustCompile(`v\d+\.\d+\.\d+`))
+func GetStringMatching(name, defval string, pattern *regexp.Regexp) (string, bool) {
+	return defaultConfig.GetStringMatching(name, defval, pattern)
+}
+
+// GetStringMatching is like the package-level GetStringMatching, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringMatching(name, defval string, pattern *regexp.Regexp) (string, bool) {
+	val, exists := c.GetString(name, defval)
+	if !exists {
+		return defval, false
+	}
+
+	if !anchoredPattern(pattern).MatchString(val) {
+		c.recordDefault(name)
+		return defval, true
+	}
+
+	return val, true
+}
+
+var (
+	anchoredPatternsLock sync.RWMutex
+	anchoredPatterns     = map[*regexp.Regexp]*regexp.Regexp{}
+)
+
+// anchoredPattern returns a copy of pattern that only matches an
+// entire string. The copy is compiled once per pattern and cached.
+func anchoredPattern(pattern *regexp.Regexp) *regexp.Regexp {
+	anchoredPatternsLock.RLock()
+	anchored, ok := anchoredPatterns[pattern]
+	anchoredPatternsLock.RUnlock()
+	if ok {
+		return anchored
+	}
+
+	anchored = regexp.MustCompile(`^(?:` + pattern.String() + `)$`)
+
+	anchoredPatternsLock.Lock()
+	anchoredPatterns[pattern] = anchored
+	anchoredPatternsLock.Unlock()
+
+	return anchored
+}
+
+// GetStringChoicesFold is like GetStringChoices, but compares the
+// value to each choice without regard to case (using
+// strings.EqualFold). When a match is found, the matching element of
+// choices is returned rather than the raw value, so that callers
+// always see the canonical spelling. If more than one choice matches,
+// the first one wins.
+//
+// Example:
+//
+//	os.Setenv("WIDGET_SIZE", "SMALL")
+//	widgetSize, _ := decouple.GetStringChoicesFold("WIDGET_SIZE", "medium", []string{"small", "medium", "large"})
+func GetStringChoicesFold(name, defval string, choices []string) (string, bool) {
+	return defaultConfig.GetStringChoicesFold(name, defval, choices)
+}
+
+// GetStringChoicesFold is like the package-level GetStringChoicesFold,
+// but looks up variables using the settings in c.
+func (c *Config) GetStringChoicesFold(name, defval string, choices []string) (string, bool) {
+	val, exists := c.GetString(name, defval)
+	if !exists {
+		return defval, false
+	}
+
+	for _, choice := range choices {
+		if strings.EqualFold(val, choice) {
+			return choice, true
+		}
+	}
+
+	c.recordDefault(name)
+	return defval, true
+}
+
+// GetChoiceIndex returns the index in choices of the value of an
+// environment variable. The comparison is case-sensitive.
+//
+// If the named variable exists and is one of choices, return (index,
+// true). If the named variable exists but is not a valid choice,
+// return (defval, true). If the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("MODE", "replica")
+//	mode, _ := decouple.GetChoiceIndex("MODE", 0, []string{"primary", "replica"})
+//	handlers[mode]()
+func GetChoiceIndex(name string, defval int, choices []string) (int, bool) {
+	return defaultConfig.GetChoiceIndex(name, defval, choices)
+}
+
+// GetChoiceIndex is like the package-level GetChoiceIndex, but looks
+// up variables using the settings in c.
+func (c *Config) GetChoiceIndex(name string, defval int, choices []string) (int, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+	val = c.applyValueCase(val)
+
+	for i, choice := range choices {
+		if val == choice {
+			return i, true
+		}
+	}
+
+	c.recordDefault(name)
+	return defval, true
+}
+
+// GetChoiceByNameOrIndex is like GetStringChoices, but also accepts
+// the 1-based position of a choice, so that "2" selects the second
+// element of choices.
+//
+// If the named variable exists and is either one of choices or a
+// valid index into choices, return (choice, true). If the named
+// variable exists but matches neither, return (defval, true). If the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("WIDGET_SIZE", "2")
+//	size, _ := decouple.GetChoiceByNameOrIndex("WIDGET_SIZE", "small", []string{"small", "medium", "large"})
+func GetChoiceByNameOrIndex(name, defval string, choices []string) (string, bool) {
+	return defaultConfig.GetChoiceByNameOrIndex(name, defval, choices)
+}
+
+// GetChoiceByNameOrIndex is like the package-level
+// GetChoiceByNameOrIndex, but looks up variables using the settings in
+// c.
+func (c *Config) GetChoiceByNameOrIndex(name, defval string, choices []string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+	val = c.applyValueCase(val)
+
+	for _, choice := range choices {
+		if val == choice {
+			return choice, true
+		}
+	}
+
+	if i, err := strconv.Atoi(val); err == nil && i >= 1 && i <= len(choices) {
+		return choices[i-1], true
+	}
+
+	c.recordDefault(name)
+	return defval, true
+}
+
+// GetStringAliased returns the value of an environment variable
+// normalized to a canonical form. aliases maps each accepted token to
+// its canonical form; the canonical forms themselves (the values in
+// aliases) are also accepted.
+//
+// If the named variable exists and is an accepted token, return
+// (canonical form, true). If the named variable exists but is not
+// recognized, return (defval, true). If the named variable does not
+// exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ENVIRONMENT", "prod")
+//	env, _ := decouple.GetStringAliased("ENVIRONMENT", "development", map[string]string{
+//		"prod": "production",
+//		"dev":  "development",
+//	})
+func GetStringAliased(name, defval string, aliases map[string]string) (string, bool) {
+	return defaultConfig.GetStringAliased(name, defval, aliases)
+}
+
+// GetStringAliased is like the package-level GetStringAliased, but
+// looks up variables using the settings in c.
+func (c *Config) GetStringAliased(name, defval string, aliases map[string]string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+	val = c.applyValueCase(val)
+
+	if canonical, ok := aliases[val]; ok {
+		return canonical, true
+	}
+
+	for _, canonical := range aliases {
+		if val == canonical {
+			return canonical, true
+		}
+	}
+
+	c.recordDefault(name)
+	return defval, true
+}
+
+// GetOneOf returns the value of an environment variable converted to
+// type T if it is a valid choice. Otherwise, returns a default value.
+//
+// The value is converted using convert. If the conversion is
+// successful and the result is one of choices, return (value, true).
+// If the conversion is successful but the result is not a valid
+// choice, return (defval, true). If the conversion fails or if the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	type Mode int
+//	parseMode := func(s string) (Mode, error) {
+//		i, err := strconv.Atoi(s)
+//		return Mode(i), err
+//	}
+//	os.Setenv("MODE", "2")
+//	mode, _ := decouple.GetOneOf("MODE", Mode(1), parseMode, []Mode{1, 2, 3})
+func GetOneOf[T comparable](name string, defval T, convert func(string) (T, error), choices []T) (T, bool) {
+	val, exists := LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := convert(val)
+	if err != nil {
+		defaultConfig.parseError(name, val, err)
+		return defval, false
+	}
+
+	for _, choice := range choices {
+		if ret == choice {
+			return ret, true
+		}
+	}
+
+	defaultConfig.recordDefault(name)
+	return defval, true
+}
+
+// GetParsed returns the value of an environment variable converted to
+// type T using parse. It is the building block for getters of types
+// that this package does not support directly.
+//
+// If the named variable exists and parse succeeds, return (value,
+// true). If parse fails or if the named variable does not exist,
+// return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("BIND_ADDR", "10.0.0.1")
+//	addr, _ := decouple.GetParsed("BIND_ADDR", net.IPv4zero, func(s string) (net.IP, error) {
+//		if ip := net.ParseIP(s); ip != nil {
+//			return ip, nil
+//		}
+//		return nil, errors.New("invalid IP address")
+//	})
+func GetParsed[T any](name string, defval T, parse func(string) (T, error)) (T, bool) {
+	val, exists := LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parse(val)
+	if err != nil {
+		defaultConfig.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetEnumStringer returns the element of all whose String method
+// returns the value of an environment variable, so that the list of
+// valid choices does not have to be kept in sync with the type.
+//
+// If the named variable exists and matches an element of all, return
+// (element, true). If the named variable exists but does not match,
+// return (defval, true). If the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("COLOR", "green")
+//	color, _ := decouple.GetEnumStringer("COLOR", Red, []Color{Red, Green, Blue})
+func GetEnumStringer[T fmt.Stringer](name string, defval T, all []T) (T, bool) {
+	val, exists := LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	for _, elem := range all {
+		if elem.String() == val {
+			return elem, true
+		}
+	}
+
+	defaultConfig.recordDefault(name)
+	return defval, true
+}
+
+// GetInt returns the value of an environment variable as an int.
+//
+// If the named variable exists, attempt to convert it to an integer.
+// If the conversion is successful, return (value, true). If the
+// conversion fails or if the named variable does not exist, return
+// (defval, false).
+//
+// The base is implied by the value's prefix: "0x" for hexadecimal,
+// "0o" or a leading "0" for octal, and "0b" for binary, so "010" is
+// read as 8. Use GetIntBase to require a particular base.
+//
+// Example:
+//
+//	os.Setenv("WIDGET_COUNT", 2)
+//	widgetCount, _ := decouple.GetInt("WIDGET_COUNT", 10)
+func GetInt(name string, defval int) (int, bool) {
+	return defaultConfig.GetInt(name, defval)
+}
+
+// GetInt is like the package-level GetInt, but looks up variables
+// using the settings in c.
+func (c *Config) GetInt(name string, defval int) (int, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseInt(val, 0, 0)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return int(ret), true
+}
+
+// basePrefixes are the prefixes accepted by GetIntBase for each
+// base.
+var basePrefixes = map[int][]string{
+	2:  {"0b", "0B"},
+	8:  {"0o", "0O"},
+	16: {"0x", "0X"},
+}
+
+// GetIntBase is like GetInt, but parses the value in the given base
+// (see strconv.ParseInt) instead of inferring it from the prefix of
+// the value. With base 10, "010" is read as 10. For bases 2, 8 and 16
+// the corresponding prefix ("0b", "0o" or "0x") is permitted but not
+// required.
+//
+// Example:
+//
+//	os.Setenv("COUNT", "010")
+//	count, _ := decouple.GetIntBase("COUNT", 0, 10)
+func GetIntBase(name string, defval, base int) (int, bool) {
+	return defaultConfig.GetIntBase(name, defval, base)
+}
+
+// GetIntBase is like the package-level GetIntBase, but looks up
+// variables using the settings in c.
+func (c *Config) GetIntBase(name string, defval, base int) (int, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	sign, digits := "", val
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	for _, prefix := range basePrefixes[base] {
+		if strings.HasPrefix(digits, prefix) {
+			digits = digits[len(prefix):]
+			break
+		}
+	}
+
+	ret, err := strconv.ParseInt(sign+digits, base, 0)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return int(ret), true
+}
+
+// GetIntInRange returns the value of environment variable as an int,
+// clamped to an explicit range.
+//
+// If the named variable exists, attempt to convert it to an integer.
+// If the conversion is successful and the value falls within the
+// given range, return (value, true). If value > maxval, return
+// (maxval, true). If value < minval, return (minval, true). If the
+// conversion fails or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("LOG_LEVEL", 2)
+//	logLevel, _ := decouple.GetIntInRange("LOG_LEVEL", 1, -1, 5)
+func GetIntInRange(name string, defval, minval, maxval int) (int, bool) {
+	return defaultConfig.GetIntInRange(name, defval, minval, maxval)
+}
+
+// GetIntInRange is like the package-level GetIntInRange, but looks up
+// variables using the settings in c.
+func (c *Config) GetIntInRange(name string, defval, minval, maxval int) (int, bool) {
+	ret, exists := c.GetInt(name, defval)
+
+	return clamp(ret, minval, maxval), exists
+}
+
+// GetComplex128 returns the value of an environment variable as a
+// complex128.
+//
+// If the named variable exists, attempt to convert it to a complex
+// number using strconv.ParseComplex. If the conversion is successful,
+// return (value, true). If the conversion fails or if the named
+// variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("COEFF", "1+2i")
+//	coeff, _ := decouple.GetComplex128("COEFF", complex(1, 0))
+func GetComplex128(name string, defval complex128) (complex128, bool) {
+	return defaultConfig.GetComplex128(name, defval)
+}
+
+// GetComplex128 is like the package-level GetComplex128, but looks up
+// variables using the settings in c.
+func (c *Config) GetComplex128(name string, defval complex128) (complex128, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseComplex(val, 128)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// Ordered is a constraint that permits any type that supports the
+// < and > operators.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// clamp returns val limited to the range [minval, maxval].
+func clamp[T Ordered](val, minval, maxval T) T {
+	switch {
+	case val < minval:
+		return minval
+	case val > maxval:
+		return maxval
+	default:
+		return val
+	}
+}
+
+// GetOrdered returns the value of an environment variable converted
+// to type T using parse, clamped to an explicit range.
+//
+// If the named variable exists and parse succeeds, the value is
+// clamped to [minval, maxval] and returned as (value, true). If parse
+// fails or if the named variable does not exist, return (defval,
+// false).
+//
+// Example:
+//
+//	os.Setenv("WORKERS", "64")
+//	workers, _ := decouple.GetOrdered("WORKERS", 4, 1, 16, strconv.Atoi)
+func GetOrdered[T Ordered](name string, defval, minval, maxval T, parse func(string) (T, error)) (T, bool) {
+	val, exists := LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parse(val)
+	if err != nil {
+		defaultConfig.parseError(name, val, err)
+		return defval, false
+	}
+
+	return clamp(ret, minval, maxval), true
+}
+
+// GetInt64InRange is like GetIntInRange, but operates on int64
+// values.
+//
+// Example:
+//
+//	os.Setenv("MAX_OFFSET", "1099511627776")
+//	maxOffset, _ := decouple.GetInt64InRange("MAX_OFFSET", 0, 0, 1<<40)
+func GetInt64InRange(name string, defval, minval, maxval int64) (int64, bool) {
+	return defaultConfig.GetInt64InRange(name, defval, minval, maxval)
+}
+
+// GetInt64InRange is like the package-level GetInt64InRange, but looks
+// up variables using the settings in c.
+func (c *Config) GetInt64InRange(name string, defval, minval, maxval int64) (int64, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseInt(val, 0, 64)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return clamp(ret, minval, maxval), true
+}
+
+// GetUintInRange is like GetIntInRange, but operates on uint64
+// values. Negative values are treated as a conversion failure and
+// return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("BUFFER_SIZE", "65536")
+//	bufferSize, _ := decouple.GetUintInRange("BUFFER_SIZE", 4096, 512, 1<<20)
+func GetUintInRange(name string, defval, minval, maxval uint64) (uint64, bool) {
+	return defaultConfig.GetUintInRange(name, defval, minval, maxval)
+}
+
+// GetUintInRange is like the package-level GetUintInRange, but looks
+// up variables using the settings in c.
+func (c *Config) GetUintInRange(name string, defval, minval, maxval uint64) (uint64, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseUint(val, 0, 64)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return clamp(ret, minval, maxval), true
+}
+
+// GetIntSliceInRange parses an environment variable as a single row
+// in a CSV document of integers, clamping each element to an explicit
+// range.
+//
+// If the named variable exists and every element can be converted,
+// each element is clamped to [minval, maxval] and the result is
+// returned as (elements, true). If any element cannot be converted or
+// if the named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("WORKER_LIMITS", "5,200,-3")
+//	limits, _ := decouple.GetIntSliceInRange("WORKER_LIMITS", nil, 0, 100)
+func GetIntSliceInRange(name string, defval []int, minval, maxval int) ([]int, bool) {
+	return defaultConfig.GetIntSliceInRange(name, defval, minval, maxval)
+}
+
+// GetIntSliceInRange is like the package-level GetIntSliceInRange, but
+// looks up variables using the settings in c.
+func (c *Config) GetIntSliceInRange(name string, defval []int, minval, maxval int) ([]int, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	ret := make([]int, len(rec))
+	for i, field := range rec {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+
+		ret[i] = clamp(n, minval, maxval)
+	}
+
+	return ret, true
+}
+
+// GetPercentage returns the value of an environment variable as a
+// fraction between 0 and 1.
+//
+// The value may be either a percentage with a trailing "%" (e.g.
+// "25%"), which is divided by 100, or a bare fraction (e.g. "0.25").
+// The result is clamped to the range [0, 1]. If the conversion is
+// successful, return (value, true). If the conversion fails or if the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("SAMPLE_RATE", "25%")
+//	sampleRate, _ := decouple.GetPercentage("SAMPLE_RATE", 0.1)
+func GetPercentage(name string, defval float64) (float64, bool) {
+	return defaultConfig.GetPercentage(name, defval)
+}
+
+// GetPercentage is like the package-level GetPercentage, but looks up
+// variables using the settings in c.
+func (c *Config) GetPercentage(name string, defval float64) (float64, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	num := strings.TrimSpace(val)
+	percent := strings.HasSuffix(num, "%")
+	num = strings.TrimSuffix(num, "%")
+
+	ret, err := strconv.ParseFloat(num, 64)
+	if err == nil && math.IsNaN(ret) {
+		err = errors.New("value must be a number")
+	}
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	if percent {
+		ret /= 100
+	}
+
+	return clamp(ret, 0, 1), true
+}
+
+// parseCents parses a decimal amount with at most two fractional
+// digits, such as "19.99", into an integer number of hundredths.
+func parseCents(val string) (int64, error) {
+	neg := strings.HasPrefix(val, "-")
+	num := strings.TrimPrefix(val, "-")
+
+	whole, frac := num, ""
+	if i := strings.Index(num, "."); i != -1 {
+		whole, frac = num[:i], num[i+1:]
+	}
+
+	if whole == "" || len(frac) > 2 || strings.TrimLeft(whole+frac, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid amount %q", val)
+	}
+
+	frac += strings.Repeat("0", 2-len(frac))
+	ret, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if neg {
+		ret = -ret
+	}
+
+	return ret, nil
+}
+
+// GetCents returns the value of an environment variable as an
+// integer number of cents, avoiding the rounding errors of floating
+// point for amounts of money.
+//
+// The value must be a decimal number with at most two digits after
+// the decimal point, so "19.99" is returned as 1999 and "20" as 2000.
+// If the conversion is successful, return (value, true). If the value
+// has more than two fractional digits, is not numeric, or if the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("PRICE", "19.99")
+//	price, _ := decouple.GetCents("PRICE", 0)
+func GetCents(name string, defval int64) (int64, bool) {
+	return defaultConfig.GetCents(name, defval)
+}
+
+// GetCents is like the package-level GetCents, but looks up variables
+// using the settings in c.
+func (c *Config) GetCents(name string, defval int64) (int64, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parseCents(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetRune returns the value of an environment variable as a single
+// rune.
+//
+// If the named variable consists of exactly one rune, return (value,
+// true). If the value is empty, contains more than one rune, or is
+// not valid UTF-8, or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("CSV_DELIM", ";")
+//	delim, _ := decouple.GetRune("CSV_DELIM", ',')
+func GetRune(name string, defval rune) (rune, bool) {
+	return defaultConfig.GetRune(name, defval)
+}
+
+// GetRune is like the package-level GetRune, but looks up variables
+// using the settings in c.
+func (c *Config) GetRune(name string, defval rune) (rune, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, size := utf8.DecodeRuneInString(val)
+	if (ret == utf8.RuneError && size <= 1) || size != len(val) {
+		c.parseError(name, val, errors.New("value must be a single character"))
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetBool returns the value of an environment variable as a
+// boolean.
+//
+// If the named variable exists, attempt to convert it to a bool. If
+// the conversion is successful, return (value, true). If the
+// conversion fails or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("DEBUG_MODE", "true")
+// 	debugMode, _ := decouple.GetBool("DEBUG_MODE")
+func GetBool(name string, defval bool) (bool, bool) {
+	return defaultConfig.GetBool(name, defval)
+}
+
+// GetBool is like the package-level GetBool, but looks up variables
+// using the settings in c.
+func (c *Config) GetBool(name string, defval bool) (bool, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseBool(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetBoolStrict returns the value of an environment variable as a
+// boolean, accepting only the literal values "true" and "false".
+//
+// Unlike GetBool, values such as "1", "t" or "True" are rejected. If
+// the named variable is exactly "true" or "false", return (value,
+// true). Otherwise, or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ALLOW_DELETE", "true")
+//	allowDelete, _ := decouple.GetBoolStrict("ALLOW_DELETE", false)
+func GetBoolStrict(name string, defval bool) (bool, bool) {
+	return defaultConfig.GetBoolStrict(name, defval)
+}
+
+// GetBoolStrict is like the package-level GetBoolStrict, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolStrict(name string, defval bool) (bool, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	switch val {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		c.parseError(name, val, errors.New(`value must be "true" or "false"`))
+		return defval, false
+	}
+}
+
+// GetBoolSource is like GetBool, but also returns the raw value of
+// the variable, which is useful when logging how a value was
+// interpreted.
+//
+// If the named variable exists and can be converted to a bool, return
+// (value, raw, true). If the conversion fails, return (defval, raw,
+// false). If the named variable does not exist, return (defval, "",
+// false).
+//
+// Example:
+//
+//	debugMode, raw, fromEnv := decouple.GetBoolSource("DEBUG_MODE", false)
+//	if !fromEnv && raw != "" {
+//		log.Printf("ignoring invalid DEBUG_MODE %q", raw)
+//	}
+func GetBoolSource(name string, defval bool) (value bool, raw string, fromEnv bool) {
+	return defaultConfig.GetBoolSource(name, defval)
+}
+
+// GetBoolSource is like the package-level GetBoolSource, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolSource(name string, defval bool) (value bool, raw string, fromEnv bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, "", false
+	}
+
+	ret, err := strconv.ParseBool(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, val, false
+	}
+
+	return ret, val, true
+}
+
+// parseBoolExtended is like strconv.ParseBool, but additionally
+// accepts "yes", "no", "y", "n", "on" and "off", in any case.
+func parseBoolExtended(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+
+	return strconv.ParseBool(val)
+}
+
+// GetBoolString returns the value of an environment variable as the
+// canonical string form of a boolean, "true" or "false", which is
+// useful when passing flags on to other programs. In addition to the
+// values accepted by GetBool, "yes", "no", "y", "n", "on" and "off"
+// are accepted, in any case.
+//
+// If the named variable exists and can be converted, return
+// ("true" or "false", true). If the conversion fails or if the named
+// variable does not exist, return (the string form of defval, false).
+//
+// Example:
+//
+//	os.Setenv("VERBOSE", "yes")
+//	verbose, _ := decouple.GetBoolString("VERBOSE", false)
+//	cmd := exec.Command("worker", "--verbose="+verbose)
+func GetBoolString(name string, defval bool) (string, bool) {
+	return defaultConfig.GetBoolString(name, defval)
+}
+
+// GetBoolString is like the package-level GetBoolString, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolString(name string, defval bool) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return strconv.FormatBool(defval), false
+	}
+
+	ret, err := parseBoolExtended(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return strconv.FormatBool(defval), false
+	}
+
+	return strconv.FormatBool(ret), true
+}
+
+// GetCSVString parses an environment variable as a single row in a
+// CSV document and returns a list of strings.
+//
+// Example:
+//
+//	os.Setenv("LIST_OF_NAMES", "alice,bob,carol")
+//	names, _ := decouple.GetCSVString("LIST_OF_NAMES", []string{})
+func GetCSVString(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetCSVString(name, defval)
+}
+
+// GetCSVString is like the package-level GetCSVString, but looks up
+// variables using the settings in c.
+func (c *Config) GetCSVString(name string, defval []string) ([]string, bool) {
+	rec, _, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	return c.transformElements(rec), true
+}
+
+// GetCSVStringE is like GetCSVString, but reports why the default was
+// used. If the named variable does not exist, it returns defval and
+// an error that wraps ErrNotSet. If the value cannot be parsed, it
+// returns defval and an error that wraps the error from encoding/csv
+// and includes the raw value.
+//
+// Example:
+//
+//	names, err := decouple.GetCSVStringE("LIST_OF_NAMES", nil)
+//	if err != nil && !errors.Is(err, decouple.ErrNotSet) {
+//		log.Fatal(err)
+//	}
+func GetCSVStringE(name string, defval []string) ([]string, error) {
+	return defaultConfig.GetCSVStringE(name, defval)
+}
+
+// GetCSVStringE is like the package-level GetCSVStringE, but looks up
+// variables using the settings in c.
+func (c *Config) GetCSVStringE(name string, defval []string) ([]string, error) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, fmt.Errorf("%s: %w", c.fullName(name), ErrNotSet)
+	}
+
+	rec, err := csv.NewReader(strings.NewReader(val)).Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, fmt.Errorf("%s: cannot parse %q: %w", c.fullName(name), val, err)
+	}
+
+	return c.transformElements(c.dropEmptyElements(rec)), nil
+}
+
+// lookupCSV looks up the named variable and parses it as a single
+// row in a CSV document. It returns the parsed row and the raw value.
+// If the variable does not exist or cannot be parsed, ok is false.
+func (c *Config) lookupCSV(name string) (rec []string, val string, ok bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return nil, "", false
+	}
+
+	r := strings.NewReader(val)
+	csvr := csv.NewReader(r)
+	rec, err := csvr.Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return nil, val, false
+	}
+
+	return c.dropEmptyElements(rec), val, true
+}
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseBytes parses a human-readable size such as "512", "10MB" or
+// "1.5GiB" into a number of bytes. Units are case-insensitive; "KB",
+// "MB", etc. are powers of 1000 and "KiB", "MiB", etc. are powers of
+// 1024.
+func parseBytes(val string) (int64, error) {
+	val = strings.TrimSpace(val)
+	i := strings.IndexFunc(val, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.')
+	})
+	if i == -1 {
+		i = len(val)
+	}
+
+	num, unit := val[:i], strings.ToLower(strings.TrimSpace(val[i:]))
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+
+	if !strings.Contains(num, ".") {
+		ret, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		if ret > math.MaxInt64/mult {
+			return 0, fmt.Errorf("size %q out of range", val)
+		}
+
+		return ret * mult, nil
+	}
+
+	ret, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit
+	// in an int64.
+	size := ret * float64(mult)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q out of range", val)
+	}
+
+	return int64(size), nil
+}
+
+// stripComment removes a trailing comment, starting at the first "#"
+// that is not inside double quotes, along with any whitespace that
+// precedes it. If afterSpace is true, a "#" only starts a comment at
+// the start of the value or after whitespace, as in a shell, so that
+// a "#" inside a word, such as in "pa#ss", is left alone.
+func stripComment(val string, afterSpace bool) string {
+	quoted := false
+	prev := ' '
+	for i, r := range val {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '#' && !quoted && (!afterSpace || unicode.IsSpace(prev)):
+			return strings.TrimRightFunc(val[:i], unicode.IsSpace)
+		}
+		prev = r
+	}
+
+	return val
+}
+
+// GetCSVStringStripComments is like GetCSVString, but first removes a
+// trailing comment from the value. A comment starts at the first "#"
+// that is not inside a double-quoted field and extends to the end of
+// the value.
+//
+// Example:
+//
+//	os.Setenv("REGIONS", "us-east-1,eu-west-1 # production set")
+//	regions, _ := decouple.GetCSVStringStripComments("REGIONS", nil)
+func GetCSVStringStripComments(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetCSVStringStripComments(name, defval)
+}
+
+// GetCSVStringStripComments is like the package-level
+// GetCSVStringStripComments, but looks up variables using the settings
+// in c.
+func (c *Config) GetCSVStringStripComments(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	rec, err := csv.NewReader(strings.NewReader(stripComment(val, false))).Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return c.transformElements(c.dropEmptyElements(rec)), true
+}
+
+// GetCSVStringBounded is like GetCSVString, but requires that the
+// number of elements is within [minLen, maxLen].
+//
+// If the named variable exists, can be parsed, and has an acceptable
+// number of elements, return (elements, true). Otherwise, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("UPSTREAMS", "10.0.0.1,10.0.0.2")
+//	upstreams, _ := decouple.GetCSVStringBounded("UPSTREAMS", []string{"127.0.0.1"}, 1, 5)
+func GetCSVStringBounded(name string, defval []string, minLen, maxLen int) ([]string, bool) {
+	return defaultConfig.GetCSVStringBounded(name, defval, minLen, maxLen)
+}
+
+// GetCSVStringBounded is like the package-level GetCSVStringBounded,
+// but looks up variables using the settings in c.
+func (c *Config) GetCSVStringBounded(name string, defval []string, minLen, maxLen int) ([]string, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	if len(rec) < minLen || len(rec) > maxLen {
+		c.parseError(name, val, fmt.Errorf("expected between %d and %d elements, got %d", minLen, maxLen, len(rec)))
+		return defval, false
+	}
+
+	return c.transformElements(rec), true
+}
+
+// GetCSVStringFirstN is like GetCSVString, but returns only the first
+// n elements, so that callers can safely index the result.
+//
+// If the named variable exists, can be parsed, and has at least n
+// elements, return (first n elements, true). Otherwise, or if n is
+// negative, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("RESOLVERS", "1.1.1.1,8.8.8.8,9.9.9.9,8.8.4.4")
+//	resolvers, _ := decouple.GetCSVStringFirstN("RESOLVERS", nil, 3)
+func GetCSVStringFirstN(name string, defval []string, n int) ([]string, bool) {
+	return defaultConfig.GetCSVStringFirstN(name, defval, n)
+}
+
+// GetCSVStringFirstN is like the package-level GetCSVStringFirstN, but
+// looks up variables using the settings in c.
+func (c *Config) GetCSVStringFirstN(name string, defval []string, n int) ([]string, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	if n < 0 {
+		c.parseError(name, val, fmt.Errorf("invalid element count %d", n))
+		return defval, false
+	}
+
+	if len(rec) < n {
+		c.parseError(name, val, fmt.Errorf("expected at least %d elements, got %d", n, len(rec)))
+		return defval, false
+	}
+
+	return c.transformElements(rec[:n]), true
+}
+
+// GetCSVStringValidated is like GetCSVString, but checks each element
+// with validate.
+//
+// If the named variable exists, can be parsed, and validate returns
+// nil for every element, return (elements, true). If validate returns
+// an error for any element, if the value cannot be parsed, or if the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("SEARCH_DOMAINS", "example,internal")
+//	domains, _ := decouple.GetCSVStringValidated("SEARCH_DOMAINS", nil, func(s string) error {
+//		if len(s) > 63 {
+//			return errors.New("label too long")
+//		}
+//		return nil
+//	})
+func GetCSVStringValidated(name string, defval []string, validate func(string) error) ([]string, bool) {
+	return defaultConfig.GetCSVStringValidated(name, defval, validate)
+}
+
+// GetCSVStringValidated is like the package-level
+// GetCSVStringValidated, but looks up variables using the settings in
+// c.
+func (c *Config) GetCSVStringValidated(name string, defval []string, validate func(string) error) ([]string, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	rec = c.transformElements(rec)
+	for _, elem := range rec {
+		if err := validate(elem); err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+	}
+
+	return rec, true
+}
+
+// GetCSVStringCount is like GetCSVString, but also returns the number
+// of elements, and treats an empty value as an empty list rather than
+// as a parse failure.
+//
+// If the named variable exists and can be parsed, return (elements,
+// len(elements), true). If the value cannot be parsed or if the named
+// variable does not exist, return (defval, len(defval), false).
+//
+// Example:
+//
+//	upstreams, n, _ := decouple.GetCSVStringCount("UPSTREAMS", nil)
+//	log.Printf("loaded %d upstreams", n)
+func GetCSVStringCount(name string, defval []string) (values []string, count int, exists bool) {
+	return defaultConfig.GetCSVStringCount(name, defval)
+}
+
+// GetCSVStringCount is like the package-level GetCSVStringCount, but
+// looks up variables using the settings in c.
+func (c *Config) GetCSVStringCount(name string, defval []string) (values []string, count int, exists bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, len(defval), false
+	}
+
+	if val == "" {
+		return []string{}, 0, true
+	}
+
+	rec, err := csv.NewReader(strings.NewReader(val)).Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, len(defval), false
+	}
+
+	rec = c.transformElements(c.dropEmptyElements(rec))
+	return rec, len(rec), true
+}
+
+// GetCSVStringSorted is like GetCSVString, but returns the unique
+// elements in sorted order, so that the result does not depend on
+// the order in which they were written.
+//
+// Elements are trimmed of surrounding whitespace and empty elements
+// are dropped. If the named variable exists and can be parsed, return
+// (elements, true); an empty value returns an empty slice. If the
+// value cannot be parsed or if the named variable does not exist,
+// return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ALLOWED_ORIGINS", "https://b.example, https://a.example,https://b.example")
+//	origins, _ := decouple.GetCSVStringSorted("ALLOWED_ORIGINS", nil)
+func GetCSVStringSorted(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetCSVStringSorted(name, defval)
+}
+
+// GetCSVStringSorted is like the package-level GetCSVStringSorted, but
+// looks up variables using the settings in c.
+func (c *Config) GetCSVStringSorted(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret := []string{}
+	if strings.TrimSpace(val) == "" {
+		return ret, true
+	}
+
+	rec, err := csv.NewReader(strings.NewReader(val)).Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range rec {
+		field = c.transformElement(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+
+		seen[field] = true
+		ret = append(ret, field)
+	}
+
+	sort.Strings(ret)
+	return ret, true
+}
+
+// GetStringSet is like GetCSVString, but returns the elements as a
+// set, so that membership can be checked without scanning a slice.
+// Duplicate elements are collapsed.
+//
+// If the named variable exists and can be parsed, return (set, true).
+// If the value cannot be parsed or if the named variable does not
+// exist, return (set of defval, false).
+//
+// Example:
+//
+//	os.Setenv("ADMINS", "alice,bob")
+//	admins, _ := decouple.GetStringSet("ADMINS", nil)
+//	if _, ok := admins[user]; ok {
+//		...
+//	}
+func GetStringSet(name string, defval []string) (map[string]struct{}, bool) {
+	return defaultConfig.GetStringSet(name, defval)
+}
+
+// GetStringSet is like the package-level GetStringSet, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringSet(name string, defval []string) (map[string]struct{}, bool) {
+	rec, exists := c.GetCSVString(name, defval)
+
+	ret := make(map[string]struct{}, len(rec))
+	for _, elem := range rec {
+		ret[elem] = struct{}{}
+	}
+
+	return ret, exists
+}
+
+// GetIndexedStrings returns a list of strings read from a sequence
+// of numbered variables: name_0, name_1, name_2, and so on. Reading
+// stops at the first missing index.
+//
+// If name_0 exists, return (elements, true). Otherwise, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("SERVER_0", "s0.example.com")
+//	os.Setenv("SERVER_1", "s1.example.com")
+//	servers, _ := decouple.GetIndexedStrings("SERVER", nil)
+func GetIndexedStrings(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetIndexedStrings(name, defval)
+}
+
+// GetIndexedStrings is like the package-level GetIndexedStrings, but
+// looks up variables using the settings in c.
+func (c *Config) GetIndexedStrings(name string, defval []string) ([]string, bool) {
+	var ret, vals []string
+	resolvedName := c.fullName(name + "_0")
+	for i := 0; ; i++ {
+		val, resolved, exists := c.lookup(fmt.Sprintf("%s_%d", name, i))
+		if !exists {
+			break
+		}
+
+		if i == 0 {
+			resolvedName = resolved
+		}
+		vals = append(vals, val)
+		ret = append(ret, c.transformElement(val))
+	}
+
+	// The numbered variables are reported to the observer as a
+	// single lookup of the first one, with the values joined by
+	// commas.
+	c.observe(name, resolvedName, strings.Join(vals, ","), len(vals) > 0)
+
+	if len(ret) == 0 {
+		c.recordDefault(name)
+		return defval, false
+	}
+
+	return c.dropEmptyElements(ret), true
+}
+
+// GetListFlexible returns a list of strings read either from a single
+// variable containing a CSV row, as described for GetCSVString, or,
+// if that variable does not exist, from numbered variables as
+// described for GetIndexedStrings.
+//
+// If either form yields at least one element, return (elements,
+// true). If the CSV variable exists but cannot be parsed, or if
+// neither form yields any elements, return (defval, false).
+//
+// Example:
+//
+//	// Either SERVERS=a,b or SERVERS_0=a and SERVERS_1=b
+//	servers, _ := decouple.GetListFlexible("SERVERS", nil)
+func GetListFlexible(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetListFlexible(name, defval)
+}
+
+// GetListFlexible is like the package-level GetListFlexible, but looks
+// up variables using the settings in c.
+func (c *Config) GetListFlexible(name string, defval []string) ([]string, bool) {
+	val, resolvedName, exists := c.lookup(name)
+	if !exists {
+		return c.GetIndexedStrings(name, defval)
+	}
+
+	c.observe(name, resolvedName, val, true)
+
+	rec, err := csv.NewReader(strings.NewReader(val)).Read()
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	rec = c.transformElements(c.dropEmptyElements(rec))
+	if len(rec) == 0 {
+		c.recordDefault(name)
+		return defval, false
+	}
+
+	return rec, true
+}
+
+// GetLinesFromFile reads a list of strings from the file named by an
+// environment variable, one element per line.
+//
+// Lines are trimmed of surrounding whitespace; blank lines and lines
+// starting with "#" are ignored. If the named variable exists and the
+// file can be read, return (lines, true). If the file cannot be read
+// or if the named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ALLOWLIST_FILE", "/etc/allow.txt")
+//	allowlist, _ := decouple.GetLinesFromFile("ALLOWLIST_FILE", nil)
+func GetLinesFromFile(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetLinesFromFile(name, defval)
+}
+
+// GetLinesFromFile is like the package-level GetLinesFromFile, but
+// looks up variables using the settings in c.
+func (c *Config) GetLinesFromFile(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	content, err := os.ReadFile(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	ret := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ret = append(ret, c.transformElement(line))
+	}
+
+	return ret, true
+}
+
+// GetCSVMap parses an environment variable as a list of records,
+// each of which is a set of key=value pairs.
+//
+// The value is split into records on fieldSep, each record is split
+// into pairs on pairSep, and each pair is split on the first "=". If
+// the value can be parsed, return (records, true). If any pair is
+// malformed or if the named variable does not exist, return (defval,
+// false).
+//
+// Example:
+//
+//	os.Setenv("BACKENDS", "name=a;weight=1,name=b;weight=2")
+//	backends, _ := decouple.GetCSVMap("BACKENDS", nil, ",", ";")
+func GetCSVMap(name string, defval []map[string]string, fieldSep, pairSep string) ([]map[string]string, bool) {
+	return defaultConfig.GetCSVMap(name, defval, fieldSep, pairSep)
+}
+
+// GetCSVMap is like the package-level GetCSVMap, but looks up
+// variables using the settings in c.
+func (c *Config) GetCSVMap(name string, defval []map[string]string, fieldSep, pairSep string) ([]map[string]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	var ret []map[string]string
+	for _, field := range strings.Split(val, fieldSep) {
+		record := make(map[string]string)
+		for _, pair := range strings.Split(field, pairSep) {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				c.parseError(name, val, fmt.Errorf("malformed pair %q", pair))
+				return defval, false
+			}
+
+			record[kv[0]] = kv[1]
+		}
+
+		ret = append(ret, record)
+	}
+
+	return ret, true
+}
+
+// parsePairs splits a value of the form "k1=v1,k2=v2" into key/value
+// pairs. An empty value contains no pairs.
+func parsePairs(val string) ([][2]string, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	var ret [][2]string
+	for _, pair := range strings.Split(val, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("malformed pair %q", pair)
+		}
+
+		ret = append(ret, [2]string{kv[0], kv[1]})
+	}
+
+	return ret, nil
+}
+
+// GetMap parses an environment variable as a comma-separated list of
+// key=value pairs and returns a map.
+//
+// If the named variable exists and can be parsed, return (map, true).
+// If any pair is malformed or if the named variable does not exist,
+// return (defval, false). If a key appears more than once, the last
+// value wins.
+//
+// Example:
+//
+//	os.Setenv("LABELS", "env=prod,team=core")
+//	labels, _ := decouple.GetMap("LABELS", nil)
+func GetMap(name string, defval map[string]string) (map[string]string, bool) {
+	return defaultConfig.GetMap(name, defval)
+}
+
+// GetMap is like the package-level GetMap, but looks up variables
+// using the settings in c.
+func (c *Config) GetMap(name string, defval map[string]string) (map[string]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	pairs, err := parsePairs(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	ret := make(map[string]string)
+	for _, kv := range pairs {
+		ret[kv[0]] = kv[1]
+	}
+
+	return ret, true
+}
+
+// GetMapFlexible is like GetMap, but also accepts a JSON object whose
+// values are all strings. If the value, with surrounding whitespace
+// removed, starts with "{", it is parsed as JSON; otherwise it is
+// parsed as key=value pairs.
+//
+// Example:
+//
+//	os.Setenv("LABELS", `{"env": "prod", "team": "core"}`)
+//	labels, _ := decouple.GetMapFlexible("LABELS", nil)
+func GetMapFlexible(name string, defval map[string]string) (map[string]string, bool) {
+	return defaultConfig.GetMapFlexible(name, defval)
+}
+
+// GetMapFlexible is like the package-level GetMapFlexible, but looks
+// up variables using the settings in c.
+func (c *Config) GetMapFlexible(name string, defval map[string]string) (map[string]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(val), "{") {
+		var ret map[string]string
+		if err := json.Unmarshal([]byte(val), &ret); err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+
+		return ret, true
+	}
+
+	pairs, err := parsePairs(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	ret := make(map[string]string)
+	for _, kv := range pairs {
+		ret[kv[0]] = kv[1]
+	}
+
+	return ret, true
+}
+
+// GetMultiMap is like GetMap, but collects the values of keys that
+// appear more than once instead of keeping only the last one. Values
+// are kept in the order in which they appear.
+//
+// Example:
+//
+//	os.Setenv("GRANTS", "role=admin,role=editor,team=core")
+//	grants, _ := decouple.GetMultiMap("GRANTS", nil)
+//	// grants["role"] is []string{"admin", "editor"}
+func GetMultiMap(name string, defval map[string][]string) (map[string][]string, bool) {
+	return defaultConfig.GetMultiMap(name, defval)
+}
+
+// GetMultiMap is like the package-level GetMultiMap, but looks up
+// variables using the settings in c.
+func (c *Config) GetMultiMap(name string, defval map[string][]string) (map[string][]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	pairs, err := parsePairs(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	ret := make(map[string][]string)
+	for _, kv := range pairs {
+		ret[kv[0]] = append(ret[kv[0]], kv[1])
+	}
+
+	return ret, true
+}
+
+// GetWeightedChoices parses an environment variable as a
+// comma-separated list of name:weight pairs, where each weight is a
+// non-negative integer, and returns a map of names to weights.
+//
+// If the named variable exists and can be parsed, return (map,
+// true). If any pair is malformed, if any weight is not a
+// non-negative integer, or if the named variable does not exist,
+// return (defval, false). If a name appears more than once, the last
+// weight wins.
+//
+// Example:
+//
+//	os.Setenv("TRAFFIC_SPLIT", "stable:3,canary:1")
+//	split, _ := decouple.GetWeightedChoices("TRAFFIC_SPLIT", map[string]int{"stable": 1})
+func GetWeightedChoices(name string, defval map[string]int) (map[string]int, bool) {
+	return defaultConfig.GetWeightedChoices(name, defval)
+}
+
+// GetWeightedChoices is like the package-level GetWeightedChoices, but
+// looks up variables using the settings in c.
+func (c *Config) GetWeightedChoices(name string, defval map[string]int) (map[string]int, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret := make(map[string]int)
+	if val == "" {
+		return ret, true
+	}
+
+	for _, field := range strings.Split(val, ",") {
+		kv := strings.SplitN(field, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			c.parseError(name, val, fmt.Errorf("malformed pair %q", field))
+			return defval, false
+		}
+
+		weight, err := strconv.Atoi(kv[1])
+		if err == nil && weight < 0 {
+			err = fmt.Errorf("negative weight %d", weight)
+		}
+		if err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+
+		ret[kv[0]] = weight
+	}
+
+	return ret, true
+}
+
+// GetMapTyped is like GetMap, but converts each value to type V using
+// convert. If convert fails for any value, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("SHARD_WEIGHTS", "a=1,b=2")
+//	weights, _ := decouple.GetMapTyped("SHARD_WEIGHTS", nil, strconv.Atoi)
+func GetMapTyped[V any](name string, defval map[string]V, convert func(string) (V, error)) (map[string]V, bool) {
+	val, exists := LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	pairs, err := parsePairs(val)
+	if err != nil {
+		defaultConfig.parseError(name, val, err)
+		return defval, false
+	}
+
+	ret := make(map[string]V)
+	for _, kv := range pairs {
+		v, err := convert(kv[1])
+		if err != nil {
+			defaultConfig.parseError(name, val, err)
+			return defval, false
+		}
+
+		ret[kv[0]] = v
+	}
+
+	return ret, true
+}
+
+// GetStringSliceMapped splits an environment variable on sep and
+// applies transform to each element.
+//
+// If the named variable exists and transform succeeds for every
+// element, return (elements, true). If transform returns an error for
+// any element or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ALLOWED_HOSTS", "Example.COM,example.org")
+//	hosts, _ := decouple.GetStringSliceMapped("ALLOWED_HOSTS", nil, ",",
+//		func(s string) (string, error) {
+//			return strings.ToLower(s), nil
+//		})
+func GetStringSliceMapped(name string, defval []string, sep string, transform func(string) (string, error)) ([]string, bool) {
+	return defaultConfig.GetStringSliceMapped(name, defval, sep, transform)
+}
+
+// GetStringSliceMapped is like the package-level GetStringSliceMapped,
+// but looks up variables using the settings in c.
+func (c *Config) GetStringSliceMapped(name string, defval []string, sep string, transform func(string) (string, error)) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	fields := c.dropEmptyElements(strings.Split(val, sep))
+	ret := make([]string, len(fields))
+	for i, field := range fields {
+		elem, err := transform(c.transformElement(field))
+		if err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+
+		ret[i] = elem
+	}
+
+	return ret, true
+}
+
+// GetJSONInto unmarshals the value of an environment variable as JSON
+// into out, which must be a pointer.
+//
+// Unlike most functions in this package, GetJSONInto distinguishes
+// between a variable that does not exist and one that cannot be
+// parsed. If the named variable does not exist, return (false, nil)
+// and leave out unchanged. If the value cannot be unmarshaled into
+// out, return (false, err). Otherwise, return (true, nil).
+//
+// Example:
+//
+//	var rules []Rule
+//	ok, err := decouple.GetJSONInto("RULES", &rules)
+func GetJSONInto(name string, out interface{}) (bool, error) {
+	return defaultConfig.GetJSONInto(name, out)
+}
+
+// GetJSONInto is like the package-level GetJSONInto, but looks up
+// variables using the settings in c.
+func (c *Config) GetJSONInto(name string, out interface{}) (bool, error) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(val), out); err != nil {
+		c.parseError(name, val, err)
+		return false, fmt.Errorf("%s: %w", c.fullName(name), err)
+	}
+
+	return true, nil
+}
+
+// GetJSONStringSlice parses an environment variable as a JSON array
+// of strings. This is useful when elements may contain commas, which
+// makes GetCSVString awkward to use.
+//
+// If the named variable exists and is a JSON array of strings, return
+// (elements, true). If the value is not a JSON array of strings or if
+// the named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("HOSTS", `["a,b", "c"]`)
+//	hosts, _ := decouple.GetJSONStringSlice("HOSTS", nil)
+func GetJSONStringSlice(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetJSONStringSlice(name, defval)
+}
+
+// GetJSONStringSlice is like the package-level GetJSONStringSlice, but
+// looks up variables using the settings in c.
+func (c *Config) GetJSONStringSlice(name string, defval []string) ([]string, bool) {
+	var ret []string
+	ok, err := c.GetJSONInto(name, &ret)
+	if !ok || err != nil {
+		return defval, false
+	}
+
+	if ret == nil {
+		c.recordDefault(name)
+		return defval, false
+	}
+
+	return c.transformElements(c.dropEmptyElements(ret)), true
+}
+
+// GetList returns the value of an environment variable as a list of
+// strings, accepting either a JSON array or a single CSV row. If the
+// value, with surrounding whitespace removed, starts with "[", it is
+// parsed as a JSON array; otherwise it is parsed as described for
+// GetCSVString.
+//
+// If the value can be parsed, return (elements, true). If the value
+// cannot be parsed or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("HOSTS", `["a.example.com", "b.example.com"]`)
+//	hosts, _ := decouple.GetList("HOSTS", nil)
+func GetList(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetList(name, defval)
+}
+
+// GetList is like the package-level GetList, but looks up variables
+// using the settings in c.
+func (c *Config) GetList(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	var ret []string
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(val), "[") {
+		err = json.Unmarshal([]byte(val), &ret)
+	} else {
+		ret, err = csv.NewReader(strings.NewReader(val)).Read()
+	}
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return c.transformElements(c.dropEmptyElements(ret)), true
+}
+
+// GetDuration returns the value of an environment variable as a
+// time.Duration, parsed using time.ParseDuration.
+//
+// If the conversion is successful, return (value, true). If the
+// conversion fails or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("HTTP_TIMEOUT", "30s")
+//	timeout, _ := decouple.GetDuration("HTTP_TIMEOUT", 10*time.Second)
+func GetDuration(name string, defval time.Duration) (time.Duration, bool) {
+	return defaultConfig.GetDuration(name, defval)
+}
+
+// GetDuration is like the package-level GetDuration, but looks up
+// variables using the settings in c.
+func (c *Config) GetDuration(name string, defval time.Duration) (time.Duration, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := time.ParseDuration(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetDurationInRange returns the value of an environment variable as
+// a time.Duration, clamped to an explicit range.
+//
+// The value is parsed as described for GetDuration. If the
+// conversion is successful, the value is clamped to [minval, maxval]
+// and returned as (value, true). If the conversion fails or if the
+// named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("HTTP_TIMEOUT", "10h")
+//	timeout, _ := decouple.GetDurationInRange("HTTP_TIMEOUT", 30*time.Second, time.Second, 5*time.Minute)
+func GetDurationInRange(name string, defval, minval, maxval time.Duration) (time.Duration, bool) {
+	return defaultConfig.GetDurationInRange(name, defval, minval, maxval)
+}
+
+// GetDurationInRange is like the package-level GetDurationInRange, but
+// looks up variables using the settings in c.
+func (c *Config) GetDurationInRange(name string, defval, minval, maxval time.Duration) (time.Duration, bool) {
+	ret, exists := c.GetDuration(name, defval)
+	if !exists {
+		return defval, false
+	}
+
+	return clamp(ret, minval, maxval), true
+}
+
+// GetDurationSlice parses an environment variable as a single row in
+// a CSV document and converts each element to a time.Duration using
+// time.ParseDuration. Surrounding whitespace is ignored.
+//
+// If the named variable exists and every element can be converted,
+// return (durations, true). If any element cannot be converted, if
+// the value is empty, or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("BACKOFF", "100ms,500ms,2s")
+//	backoff, _ := decouple.GetDurationSlice("BACKOFF", []time.Duration{time.Second})
+func GetDurationSlice(name string, defval []time.Duration) ([]time.Duration, bool) {
+	return defaultConfig.GetDurationSlice(name, defval)
+}
+
+// GetDurationSlice is like the package-level GetDurationSlice, but
+// looks up variables using the settings in c.
+func (c *Config) GetDurationSlice(name string, defval []time.Duration) ([]time.Duration, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	ret := make([]time.Duration, len(rec))
+	for i, field := range rec {
+		d, err := time.ParseDuration(strings.TrimSpace(field))
+		if err != nil {
+			c.parseError(name, val, err)
+			return defval, false
+		}
+
+		ret[i] = d
+	}
+
+	return ret, true
+}
+
+// GetTimeUnix returns the value of an environment variable as a
+// time.Time, interpreting the value as an integer number of seconds
+// since the Unix epoch. Use GetTimeUnixMilli for values expressed in
+// milliseconds.
+//
+// If the conversion is successful, return (value, true). If the
+// conversion fails or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("START_AT", "1700000000")
+//	startAt, _ := decouple.GetTimeUnix("START_AT", time.Now())
+func GetTimeUnix(name string, defval time.Time) (time.Time, bool) {
+	return defaultConfig.GetTimeUnix(name, defval)
+}
+
+// GetTimeUnix is like the package-level GetTimeUnix, but looks up
+// variables using the settings in c.
+func (c *Config) GetTimeUnix(name string, defval time.Time) (time.Time, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return time.Unix(ret, 0), true
+}
+
+// GetTimeUnixMilli is like GetTimeUnix, but interprets the value as
+// an integer number of milliseconds since the Unix epoch.
+//
+// Example:
+//
+//	os.Setenv("START_AT", "1700000000000")
+//	startAt, _ := decouple.GetTimeUnixMilli("START_AT", time.Now())
+func GetTimeUnixMilli(name string, defval time.Time) (time.Time, bool) {
+	return defaultConfig.GetTimeUnixMilli(name, defval)
+}
+
+// GetTimeUnixMilli is like the package-level GetTimeUnixMilli, but
+// looks up variables using the settings in c.
+func (c *Config) GetTimeUnixMilli(name string, defval time.Time) (time.Time, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return time.Unix(ret/1000, (ret%1000)*int64(time.Millisecond)), true
+}
+
+// GetStringSliceUnique splits an environment variable on sep and
+// returns the unique elements in the order in which they first
+// appear.
+//
+// Elements are trimmed of surrounding whitespace and empty elements
+// are dropped. If the named variable exists, return (elements,
+// true); a value with no non-empty elements returns an empty slice.
+// If the named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("FEATURES", "a,b,a,c")
+//	features, _ := decouple.GetStringSliceUnique("FEATURES", nil, ",")
+func GetStringSliceUnique(name string, defval []string, sep string) ([]string, bool) {
+	return defaultConfig.GetStringSliceUnique(name, defval, sep)
+}
+
+// GetStringSliceUnique is like the package-level GetStringSliceUnique,
+// but looks up variables using the settings in c.
+func (c *Config) GetStringSliceUnique(name string, defval []string, sep string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret := []string{}
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(val, sep) {
+		field = c.transformElement(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+
+		seen[field] = true
+		ret = append(ret, field)
+	}
+
+	return ret, true
+}
+
+// GetTokens splits an environment variable into tokens separated by
+// any combination of commas and whitespace, which is more forgiving
+// than CSV for lists typed by hand. Empty tokens are dropped.
+//
+// If the named variable exists, return (tokens, true); a value that
+// contains only separators returns an empty slice. If the named
+// variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("REGIONS", "us-east-1, eu-west-1 ,ap-south-1")
+//	regions, _ := decouple.GetTokens("REGIONS", nil)
+func GetTokens(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetTokens(name, defval)
+}
+
+// GetTokens is like the package-level GetTokens, but looks up
+// variables using the settings in c.
+func (c *Config) GetTokens(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret := strings.FieldsFunc(val, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	return c.transformElements(ret), true
+}
+
+// splitArgv splits val into words using shell-like rules. Words are
+// separated by whitespace. Inside single quotes every character is
+// literal; inside double quotes a backslash escapes only '"' and
+// '\'; elsewhere a backslash escapes any character.
+func splitArgv(val string) ([]string, error) {
+	ret := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(val)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				ret = append(ret, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+
+	if inWord {
+		ret = append(ret, word.String())
+	}
+
+	return ret, nil
+}
+
+// GetArgv splits an environment variable into a list of arguments
+// using shell-like quoting rules: arguments are separated by
+// whitespace, and single quotes, double quotes and backslashes can be
+// used to include whitespace or quotes in an argument. No other shell
+// processing (such as variable expansion) is performed.
+//
+// If the named variable exists and can be split, return (arguments,
+// true). If the value contains an unterminated quote or if the named
+// variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("EXTRA_ARGS", `--label "hello world" --verbose`)
+//	args, _ := decouple.GetArgv("EXTRA_ARGS", nil)
+func GetArgv(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetArgv(name, defval)
+}
+
+// GetArgv is like the package-level GetArgv, but looks up variables
+// using the settings in c.
+func (c *Config) GetArgv(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := splitArgv(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return c.transformElements(c.dropEmptyElements(ret)), true
+}
+
+// GetStringSliceChoicesMax parses an environment variable as a single
+// row in a CSV document, as described for GetCSVString, and checks
+// that it has no more than maxCount elements, each of which is one of
+// choices.
+//
+// If the named variable exists, can be parsed, and satisfies both
+// checks, return (elements, true). Otherwise, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("FEATURES", "search,export")
+//	features, _ := decouple.GetStringSliceChoicesMax("FEATURES", nil,
+//		[]string{"search", "export", "beta"}, 10)
+func GetStringSliceChoicesMax(name string, defval []string, choices []string, maxCount int) ([]string, bool) {
+	return defaultConfig.GetStringSliceChoicesMax(name, defval, choices, maxCount)
+}
+
+// GetStringSliceChoicesMax is like the package-level
+// GetStringSliceChoicesMax, but looks up variables using the settings
+// in c.
+func (c *Config) GetStringSliceChoicesMax(name string, defval []string, choices []string, maxCount int) ([]string, bool) {
+	rec, val, ok := c.lookupCSV(name)
+	if !ok {
+		return defval, false
+	}
+
+	if len(rec) > maxCount {
+		c.parseError(name, val, fmt.Errorf("expected at most %d elements, got %d", maxCount, len(rec)))
+		return defval, false
+	}
+
+	valid := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		valid[choice] = true
+	}
+
+	rec = c.transformElements(rec)
+	for _, elem := range rec {
+		if !valid[elem] {
+			c.parseError(name, val, fmt.Errorf("%q is not a valid choice", elem))
+			return defval, false
+		}
+	}
+
+	return rec, true
+}
+
+// parseUUID parses a UUID in the canonical 8-4-4-4-12 form, e.g.
+// "123e4567-e89b-12d3-a456-426614174000".
+func parseUUID(val string) ([16]byte, error) {
+	var ret [16]byte
+
+	groups := strings.Split(val, "-")
+	if len(groups) != 5 {
+		return ret, fmt.Errorf("invalid UUID %q", val)
+	}
+
+	offset := 0
+	for i, size := range []int{8, 4, 4, 4, 12} {
+		if len(groups[i]) != size {
+			return ret, fmt.Errorf("invalid UUID %q", val)
+		}
+
+		if _, err := hex.Decode(ret[offset:], []byte(groups[i])); err != nil {
+			return ret, fmt.Errorf("invalid UUID %q", val)
+		}
+
+		offset += size / 2
+	}
+
+	return ret, nil
+}
+
+// GetUUID returns the value of an environment variable as a UUID.
+//
+// The value must be in the canonical 8-4-4-4-12 form; hex digits may
+// be in upper or lower case. If the conversion is successful, return
+// (value, true). If the conversion fails or if the named variable
+// does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("TENANT_ID", "123e4567-e89b-12d3-a456-426614174000")
+//	tenantID, _ := decouple.GetUUID("TENANT_ID", [16]byte{})
+func GetUUID(name string, defval [16]byte) ([16]byte, bool) {
+	return defaultConfig.GetUUID(name, defval)
+}
+
+// GetUUID is like the package-level GetUUID, but looks up variables
+// using the settings in c.
+func (c *Config) GetUUID(name string, defval [16]byte) ([16]byte, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parseUUID(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// Version is a semantic version, as returned by GetSemver.
+type Version struct {
+	Major, Minor, Patch int
+	PreRelease          string
+}
+
+// parseSemver parses a version of the form MAJOR.MINOR.PATCH or
+// MAJOR.MINOR.PATCH-PRERELEASE, with an optional leading "v".
+func parseSemver(val string) (Version, error) {
+	var ret Version
+
+	core := strings.TrimPrefix(val, "v")
+	if i := strings.Index(core, "-"); i != -1 {
+		core, ret.PreRelease = core[:i], core[i+1:]
+		if ret.PreRelease == "" {
+			return Version{}, fmt.Errorf("invalid version %q", val)
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q", val)
+	}
+
+	for i, dest := range []*int{&ret.Major, &ret.Minor, &ret.Patch} {
+		n, err := strconv.ParseUint(parts[i], 10, 31)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q", val)
+		}
+
+		*dest = int(n)
+	}
+
+	return ret, nil
+}
+
+// GetSemver returns the value of an environment variable as a
+// semantic version.
+//
+// The value must have the form MAJOR.MINOR.PATCH, optionally followed
+// by "-" and a pre-release identifier (e.g. "2.0.0-rc1"), and may
+// start with "v". If the conversion is successful, return (value,
+// true). If the conversion fails or if the named variable does not
+// exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("MIN_VERSION", "1.4.2")
+//	minVersion, _ := decouple.GetSemver("MIN_VERSION", decouple.Version{1, 0, 0, ""})
+func GetSemver(name string, defval Version) (Version, bool) {
+	return defaultConfig.GetSemver(name, defval)
+}
+
+// GetSemver is like the package-level GetSemver, but looks up
+// variables using the settings in c.
+func (c *Config) GetSemver(name string, defval Version) (Version, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parseSemver(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetHex returns the value of an environment variable decoded from
+// hexadecimal.
+//
+// An optional "0x" or "0X" prefix is removed before decoding. If the
+// named variable exists and can be decoded, return (value, true). If
+// the value is not valid hexadecimal (including values with an odd
+// number of digits) or if the named variable does not exist, return
+// (defval, false).
+//
+// Example:
+//
+//	os.Setenv("NODE_ID", "0xdeadbeef")
+//	nodeID, _ := decouple.GetHex("NODE_ID", nil)
+func GetHex(name string, defval []byte) ([]byte, bool) {
+	return defaultConfig.GetHex(name, defval)
+}
+
+// GetHex is like the package-level GetHex, but looks up variables
+// using the settings in c.
+func (c *Config) GetHex(name string, defval []byte) ([]byte, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	digits := val
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits = digits[2:]
+	}
+
+	ret, err := hex.DecodeString(digits)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// parseColor parses a hex color code of the form "rgb", "rrggbb" or
+// "rrggbbaa", with an optional leading "#". Colors without an alpha
+// component are opaque.
+func parseColor(val string) (color.RGBA, error) {
+	digits := strings.TrimPrefix(val, "#")
+
+	switch len(digits) {
+	case 3:
+		digits = string([]byte{
+			digits[0], digits[0],
+			digits[1], digits[1],
+			digits[2], digits[2],
+		})
+		fallthrough
+	case 6:
+		digits += "ff"
+	case 8:
+	default:
+		return color.RGBA{}, fmt.Errorf("invalid color %q", val)
+	}
+
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return color.RGBA{R: b[0], G: b[1], B: b[2], A: b[3]}, nil
+}
+
+// GetColor returns the value of an environment variable as a color,
+// parsed from a hex color code such as "#fff", "#1a2b3c" or
+// "#1a2b3c80". The leading "#" is optional, and the 8-digit form
+// includes an alpha component; other forms are opaque.
+//
+// If the named variable exists and can be parsed, return (value,
+// true). If the value is not a valid color or if the named variable
+// does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("ACCENT", "#1a2b3c")
+//	accent, _ := decouple.GetColor("ACCENT", color.RGBA{A: 0xff})
+func GetColor(name string, defval color.RGBA) (color.RGBA, bool) {
+	return defaultConfig.GetColor(name, defval)
+}
+
+// GetColor is like the package-level GetColor, but looks up variables
+// using the settings in c.
+func (c *Config) GetColor(name string, defval color.RGBA) (color.RGBA, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parseColor(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetBytes returns the value of an environment variable as a number
+// of bytes.
+//
+// The value may be a plain integer or a number followed by a unit
+// such as "KB", "MiB" or "GB". Units are case-insensitive; "KB", "MB",
+// "GB" and "TB" are powers of 1000, while "KiB", "MiB", "GiB" and
+// "TiB" are powers of 1024. If the conversion is successful, return
+// (value, true). If the conversion fails or if the named variable
+// does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("CACHE_SIZE", "512MiB")
+//	cacheSize, _ := decouple.GetBytes("CACHE_SIZE", 64<<20)
+func GetBytes(name string, defval int64) (int64, bool) {
+	return defaultConfig.GetBytes(name, defval)
+}
+
+// GetBytes is like the package-level GetBytes, but looks up variables
+// using the settings in c.
+func (c *Config) GetBytes(name string, defval int64) (int64, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, err := parseBytes(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetBytesInRange returns the value of an environment variable as a
+// number of bytes, clamped to an explicit range.
+//
+// The value is parsed as described for GetBytes. If the conversion
+// is successful, the value is clamped to [minval, maxval] and
+// returned as (value, true). If the conversion fails or if the named
+// variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("CACHE_SIZE", "100GB")
+//	cacheSize, _ := decouple.GetBytesInRange("CACHE_SIZE", 1<<30, 0, 10e9)
+func GetBytesInRange(name string, defval, minval, maxval int64) (int64, bool) {
+	return defaultConfig.GetBytesInRange(name, defval, minval, maxval)
+}
+
+// GetBytesInRange is like the package-level GetBytesInRange, but looks
+// up variables using the settings in c.
+func (c *Config) GetBytesInRange(name string, defval, minval, maxval int64) (int64, bool) {
+	ret, exists := c.GetBytes(name, defval)
+	if !exists {
+		return defval, false
+	}
+
+	return clamp(ret, minval, maxval), true
+}
+
+// GetStringf is like GetString, but the variable name is built by
+// calling fmt.Sprintf with format and args. The prefix configured
+// with SetPrefix is applied to the formatted name.
+//
+// Example:
+//
+//	for i := 0; i < shardCount; i++ {
+//		host, _ := decouple.GetStringf("", "SHARD_%d_HOST", i)
+//	}
+func GetStringf(defval string, format string, args ...interface{}) (string, bool) {
+	return defaultConfig.GetStringf(defval, format, args...)
+}
+
+// GetStringf is like the package-level GetStringf, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringf(defval string, format string, args ...interface{}) (string, bool) {
+	return c.GetString(fmt.Sprintf(format, args...), defval)
+}
+
+// GetIntf is like GetInt, but the variable name is built by calling
+// fmt.Sprintf with format and args. The prefix configured with
+// SetPrefix is applied to the formatted name.
+//
+// Example:
+//
+//	port, _ := decouple.GetIntf(5432, "SHARD_%d_PORT", i)
+func GetIntf(defval int, format string, args ...interface{}) (int, bool) {
+	return defaultConfig.GetIntf(defval, format, args...)
+}
+
+// GetIntf is like the package-level GetIntf, but looks up variables
+// using the settings in c.
+func (c *Config) GetIntf(defval int, format string, args ...interface{}) (int, bool) {
+	return c.GetInt(fmt.Sprintf(format, args...), defval)
+}
+
+// parseHostPort splits val into a host and a port, requiring the port
+// to be a number between 1 and 65535.
+func parseHostPort(val string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(val)
+	if err != nil {
+		return "", 0, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("port %d out of range", port)
+	}
+
+	return host, port, nil
+}
+
+// GetHostPort returns the value of an environment variable as a
+// network address of the form "host:port", such as ":8080" or
+// "0.0.0.0:9000".
+//
+// If the named variable exists and contains a valid address with a
+// port between 1 and 65535, return (host, port, value, true). If the
+// value is not a valid address or if the named variable does not
+// exist, return the components of defval along with defval and
+// false.
+//
+// Example:
+//
+//	os.Setenv("LISTEN_ADDR", "0.0.0.0:9000")
+//	_, _, addr, _ := decouple.GetHostPort("LISTEN_ADDR", ":8080")
+func GetHostPort(name, defval string) (host string, port int, raw string, ok bool) {
+	return defaultConfig.GetHostPort(name, defval)
+}
+
+// GetHostPort is like the package-level GetHostPort, but looks up
+// variables using the settings in c.
+func (c *Config) GetHostPort(name, defval string) (host string, port int, raw string, ok bool) {
+	val, exists := c.LookupEnv(name)
+	if exists {
+		host, port, err := parseHostPort(val)
+		if err == nil {
+			return host, port, val, true
+		}
+
+		c.parseError(name, val, err)
+	}
+
+	host, port, _ = parseHostPort(defval)
+	return host, port, defval, false
+}
+
+// GetPath returns the value of an environment variable as a path to
+// a file or directory that must exist.
+//
+// If the named variable exists and names an existing path, return
+// (value, true). If the path does not exist (or cannot be accessed)
+// or if the named variable does not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("TLS_CERT", "/etc/pki/tls/certs/server.crt")
+//	certPath, _ := decouple.GetPath("TLS_CERT", "")
+func GetPath(name, defval string) (string, bool) {
+	return defaultConfig.GetPath(name, defval)
+}
+
+// GetPath is like the package-level GetPath, but looks up variables
+// using the settings in c.
+func (c *Config) GetPath(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	if _, err := os.Stat(val); err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return val, true
+}
+
+// GetDir is like GetPath, but additionally requires that the path is
+// a directory.
+//
+// Example:
+//
+//	os.Setenv("DATA_DIR", "/var/lib/myapp")
+//	dataDir, _ := decouple.GetDir("DATA_DIR", "/tmp")
+func GetDir(name, defval string) (string, bool) {
+	return defaultConfig.GetDir(name, defval)
+}
+
+// GetDir is like the package-level GetDir, but looks up variables
+// using the settings in c.
+func (c *Config) GetDir(name, defval string) (string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	info, err := os.Stat(val)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", val)
+	}
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	return val, true
+}
+
+// GetGlob treats the value of an environment variable as a glob
+// pattern, such as "/etc/plugins/*.so", and returns the sorted list
+// of matching paths as returned by filepath.Glob.
+//
+// If the named variable exists and is a valid pattern, return
+// (matches, true); a pattern that matches nothing returns an empty
+// slice. If the pattern is malformed or if the named variable does
+// not exist, return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("PLUGINS", "/etc/plugins/*.so")
+//	plugins, _ := decouple.GetGlob("PLUGINS", nil)
+func GetGlob(name string, defval []string) ([]string, bool) {
+	return defaultConfig.GetGlob(name, defval)
+}
+
+// GetGlob is like the package-level GetGlob, but looks up variables
+// using the settings in c.
+func (c *Config) GetGlob(name string, defval []string) ([]string, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	matches, err := filepath.Glob(val)
+	if err != nil {
+		c.parseError(name, val, err)
+		return defval, false
+	}
+
+	if matches == nil {
+		matches = []string{}
+	}
+
+	sort.Strings(matches)
+	return matches, true
+}
+
+// Log levels returned by GetLogLevel.
+const (
+	LogLevelDebug = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+var logLevels = map[string]int{
+	"debug":   LogLevelDebug,
+	"info":    LogLevelInfo,
+	"warn":    LogLevelWarn,
+	"warning": LogLevelWarn,
+	"error":   LogLevelError,
+}
+
+// GetLogLevel returns the value of an environment variable as a log
+// level.
+//
+// The names "debug", "info", "warn" (or "warning") and "error" are
+// recognized regardless of case and map to LogLevelDebug,
+// LogLevelInfo, LogLevelWarn and LogLevelError respectively. If the
+// named variable is a recognized level, return (level, true). If the
+// level is not recognized or if the named variable does not exist,
+// return (defval, false).
+//
+// Example:
+//
+//	os.Setenv("LOG_LEVEL", "warn")
+//	logLevel, _ := decouple.GetLogLevel("LOG_LEVEL", decouple.LogLevelInfo)
+func GetLogLevel(name string, defval int) (int, bool) {
+	return defaultConfig.GetLogLevel(name, defval)
+}
+
+// GetLogLevel is like the package-level GetLogLevel, but looks up
+// variables using the settings in c.
+func (c *Config) GetLogLevel(name string, defval int) (int, bool) {
+	val, exists := c.LookupEnv(name)
+	if !exists {
+		return defval, false
+	}
+
+	ret, ok := logLevels[strings.ToLower(val)]
+	if !ok {
+		c.parseError(name, val, fmt.Errorf("unknown log level %q", val))
+		return defval, false
+	}
+
+	return ret, true
+}
+
+// GetStringPtr returns a pointer to the value of the named variable,
+// or nil if the variable does not exist. This distinguishes a
+// variable that is set to the empty string from one that is not set
+// at all.
+//
+// Example:
+//
+//	settings.Region = decouple.GetStringPtr("REGION")
+func GetStringPtr(name string) *string {
+	return defaultConfig.GetStringPtr(name)
+}
+
+// GetStringPtr is like the package-level GetStringPtr, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringPtr(name string) *string {
+	val, exists := c.GetString(name, "")
+	if !exists {
+		return nil
+	}
+
+	return &val
+}
+
+// GetIntPtr returns a pointer to the value of the named variable as
+// an int, or nil if the variable does not exist or cannot be
+// converted.
+func GetIntPtr(name string) *int {
+	return defaultConfig.GetIntPtr(name)
+}
+
+// GetIntPtr is like the package-level GetIntPtr, but looks up
+// variables using the settings in c.
+func (c *Config) GetIntPtr(name string) *int {
+	val, exists := c.GetInt(name, 0)
+	if !exists {
+		return nil
+	}
+
+	return &val
+}
+
+// GetBoolPtr returns a pointer to the value of the named variable as
+// a bool, or nil if the variable does not exist or cannot be
+// converted.
+func GetBoolPtr(name string) *bool {
+	return defaultConfig.GetBoolPtr(name)
+}
+
+// GetBoolPtr is like the package-level GetBoolPtr, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolPtr(name string) *bool {
+	val, exists := c.GetBool(name, false)
+	if !exists {
+		return nil
+	}
+
+	return &val
+}
+
+// GetStringFunc is like GetString, but calls defaultFn to compute the
+// default value, and only does so if the named variable does not
+// exist. This avoids the cost of computing a default that is rarely
+// needed.
+//
+// Example:
+//
+//	hostname, _ := decouple.GetStringFunc("HOSTNAME", func() string {
+//		name, _ := os.Hostname()
+//		return name
+//	})
+func GetStringFunc(name string, defaultFn func() string) (string, bool) {
+	return defaultConfig.GetStringFunc(name, defaultFn)
+}
+
+// GetStringFunc is like the package-level GetStringFunc, but looks up
+// variables using the settings in c.
+func (c *Config) GetStringFunc(name string, defaultFn func() string) (string, bool) {
+	val, exists := c.GetString(name, "")
+	if !exists {
+		return defaultFn(), false
+	}
+
+	return val, true
+}
+
+// GetIntFunc is like GetInt, but calls defaultFn to compute the
+// default value, and only does so if the named variable does not
+// exist or cannot be converted.
+func GetIntFunc(name string, defaultFn func() int) (int, bool) {
+	return defaultConfig.GetIntFunc(name, defaultFn)
+}
+
+// GetIntFunc is like the package-level GetIntFunc, but looks up
+// variables using the settings in c.
+func (c *Config) GetIntFunc(name string, defaultFn func() int) (int, bool) {
+	val, exists := c.GetInt(name, 0)
+	if !exists {
+		return defaultFn(), false
+	}
+
+	return val, true
+}
+
+// GetBoolFunc is like GetBool, but calls defaultFn to compute the
+// default value, and only does so if the named variable does not
+// exist or cannot be converted.
+func GetBoolFunc(name string, defaultFn func() bool) (bool, bool) {
+	return defaultConfig.GetBoolFunc(name, defaultFn)
+}
+
+// GetBoolFunc is like the package-level GetBoolFunc, but looks up
+// variables using the settings in c.
+func (c *Config) GetBoolFunc(name string, defaultFn func() bool) (bool, bool) {
+	val, exists := c.GetBool(name, false)
+	if !exists {
+		return defaultFn(), false
+	}
+
+	return val, true
+}
+
+// BindString sets *p to the value of the named variable as returned
+// by GetString. It is intended to be used alongside flag.StringVar.
+//
+// Example:
+//
+//	var configPath string
+//	decouple.BindString(&configPath, "CONFIG_PATH", "/etc/myapp.yaml")
+func BindString(p *string, name, defval string) {
+	defaultConfig.BindString(p, name, defval)
+}
+
+// BindString is like the package-level BindString, but looks up
+// variables using the settings in c.
+func (c *Config) BindString(p *string, name, defval string) {
+	*p, _ = c.GetString(name, defval)
+}
+
+// BindInt sets *p to the value of the named variable as returned by
+// GetInt.
+//
+// Example:
+//
+//	var port int
+//	decouple.BindInt(&port, "PORT", 8080)
+func BindInt(p *int, name string, defval int) {
+	defaultConfig.BindInt(p, name, defval)
+}
+
+// BindInt is like the package-level BindInt, but looks up variables
+// using the settings in c.
+func (c *Config) BindInt(p *int, name string, defval int) {
+	*p, _ = c.GetInt(name, defval)
+}
+
+// BindBool sets *p to the value of the named variable as returned by
+// GetBool.
+//
+// Example:
+//
+//	var debug bool
+//	decouple.BindBool(&debug, "DEBUG", false)
+func BindBool(p *bool, name string, defval bool) {
+	defaultConfig.BindBool(p, name, defval)
+}
+
+// BindBool is like the package-level BindBool, but looks up variables
+// using the settings in c.
+func (c *Config) BindBool(p *bool, name string, defval bool) {
+	*p, _ = c.GetBool(name, defval)
+}
+
+// DumpAll returns all environment variables whose names start with
+// the prefix configured with SetPrefix, keyed by name with the prefix
+// removed. It is intended for diagnostics, such as showing the
+// effective configuration of a program.
+//
+// Example:
+//
+//	decouple.SetPrefix("APP_")
+//	for name, value := range decouple.DumpAll() {
+//		fmt.Printf("%s=%s\n", name, value)
+//	}
+func DumpAll() map[string]string {
+	return defaultConfig.DumpAll()
+}
+
+// Snapshot records the current process environment and returns a
+// function that restores it: variables set after the snapshot was
+// taken are unset, and variables that were modified or unset are
+// restored to their recorded values.
+//
+// Example:
+//
+//	restore := decouple.Snapshot()
+//	defer restore()
+//	os.Setenv("PORT", "9000")
+func Snapshot() func() {
+	saved := make(map[string]string)
+	for _, entry := range os.Environ() {
+		name, val := splitEnviron(entry)
+		saved[name] = val
+	}
+
+	return func() {
+		for _, entry := range os.Environ() {
+			name, _ := splitEnviron(entry)
+			if _, ok := saved[name]; !ok {
+				os.Unsetenv(name)
+			}
+		}
+
+		for name, val := range saved {
+			if cur, ok := os.LookupEnv(name); !ok || cur != val {
+				os.Setenv(name, val)
+			}
+		}
+	}
+}
+
+// Load loads environment variables from the named files, or from
+// '.env' if no filenames are provided, in the same way as
+// godotenv.Load. Variables that are already set are not changed.
+//
+// Load variables from '.env':
+//
+//	decouple.Load()
+//
+// Load variables from 'production.env':
+//
+//	decouple.Load("production.env")
+func Load(filenames ...string) error {
+	return loadFiles(filenames, false)
+}
+
+// Overload is like godotenv.Overload. It behaves like Load, except
+// that values from the named files replace variables that are already
+// set in the environment.
+//
+// Example:
+//
+//	decouple.Overload("production.env")
+func Overload(filenames ...string) error {
+	return loadFiles(filenames, true)
+}
+
+// loadOrigin records the file that set a variable, and the value it
+// was set to.
+type loadOrigin struct {
+	filename string
+	value    string
+}
+
+var (
+	loadOriginsMu sync.Mutex
+	loadOrigins   = make(map[string]loadOrigin)
+)
+
+// loadFiles implements Load and Overload. It sets variables from each
+// of the named files in turn, recording the file that set each
+// variable for use by Source. Unless overload is true, variables that
+// are already set are left alone.
+func loadFiles(filenames []string, overload bool) error {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	for _, filename := range filenames {
+		env, err := godotenv.Read(filename)
+		if err != nil {
+			return err
+		}
+
+		loadOriginsMu.Lock()
+		for name, val := range env {
+			if _, exists := os.LookupEnv(name); exists && !overload {
+				continue
+			}
+
+			os.Setenv(name, val)
+			loadOrigins[name] = loadOrigin{filename: filename, value: val}
+		}
+		loadOriginsMu.Unlock()
+	}
+
+	return nil
+}
+
+// Source returns the value of an environment variable along with
+// where it came from. The prefix configured with SetPrefix is
+// applied to name.
+//
+// If the variable was set by Load, Overload, or one of the functions
+// built on them, origin is the name of the file it was read from. If
+// it was set in some other way, or has been changed since it was
+// loaded, origin is "os". If the variable does not exist, return
+// ("", "", false).
+//
+// Example:
+//
+//	decouple.Load("production.env")
+//	val, origin, _ := decouple.Source("DATABASE_URL")
+//	fmt.Printf("DATABASE_URL=%s (from %s)\n", val, origin)
+func Source(name string) (value string, origin string, ok bool) {
+	name = defaultConfig.fullName(name)
+
+	val, exists := os.LookupEnv(name)
+	if !exists {
+		return "", "", false
+	}
+
+	loadOriginsMu.Lock()
+	defer loadOriginsMu.Unlock()
+
+	if o, ok := loadOrigins[name]; ok && o.value == val {
+		return val, o.filename, true
+	}
+
+	return val, "os", true
+}
+
+// LoadLayered loads the named files in order, such that values from
+// later files replace values from earlier ones (and values already
+// set in the environment). Files that do not exist are skipped.
+//
+// Example:
+//
+//	decouple.LoadLayered(".env", ".env.production")
+func LoadLayered(filenames ...string) error {
+	return loadExisting(Overload, filenames)
+}
+
+// LoadIfExists is like Load, but skips files that do not exist, so
+// that optional files can be named explicitly. It still returns an
+// error if a file exists but cannot be read or parsed.
+//
+// Example:
+//
+//	decouple.LoadIfExists(".env.local", ".env")
+func LoadIfExists(filenames ...string) error {
+	return loadExisting(Load, filenames)
+}
+
+// LoadFirst loads the first of the named files that exists, as Load
+// would, and returns its name. This is useful when configuration may
+// be in one of several locations. If none of the files exist, it
+// returns an error listing them.
+//
+// Example:
+//
+//	filename, err := decouple.LoadFirst(".env", filepath.Join(home, ".myapp.env"), "/etc/myapp.env")
+func LoadFirst(filenames ...string) (string, error) {
+	for _, filename := range filenames {
+		if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		return filename, Load(filename)
+	}
+
+	return "", fmt.Errorf("none of %s exist", strings.Join(filenames, ", "))
+}
+
+// loadExisting calls load for each of the named files that exists.
+func loadExisting(load func(...string) error, filenames []string) error {
+	for _, filename := range filenames {
+		if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err := load(filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mustLoad(load func(...string) error, filenames []string) {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	for _, filename := range filenames {
+		if err := load(filename); err != nil {
+			panic(fmt.Sprintf("decouple: failed to load %s: %v", filename, err))
+		}
+	}
+}
+
+// MustLoad is like Load, but panics if any of the named files cannot
+// be loaded. The panic message includes the name of the failing file
+// and the underlying error.
+//
+// MustLoad panics whenever Load would return an error: when a file
+// does not exist or cannot be read, or when it cannot be parsed. This
+// includes calling MustLoad with no arguments when there is no
+// '.env' file.
+func MustLoad(filenames ...string) {
+	mustLoad(Load, filenames)
+}
+
+// MustOverload is like Overload, but panics under the same conditions
+// as MustLoad.
+func MustOverload(filenames ...string) {
+	mustLoad(Overload, filenames)
 }
@@ -1,9 +1,22 @@
 package decouple
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"image/color"
+	"math"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -48,6 +61,41 @@ func (t *TestSuite) TestGetIntNotExists() {
 	t.Equal(have, expected)
 }
 
+func (t *TestSuite) TestGetIntBaseDecimal() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "010"))
+	have, exists := GetIntBase("TEST_VAR_EXISTS", 0, 10)
+	t.True(exists)
+	t.Equal(have, 10)
+
+	have, exists = GetInt("TEST_VAR_EXISTS", 0)
+	t.True(exists)
+	t.Equal(have, 8)
+}
+
+func (t *TestSuite) TestGetIntBaseHex() {
+	for _, val := range []string{"0x1f", "1f", "0X1F"} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetIntBase("TEST_VAR_EXISTS", 0, 16)
+		t.True(exists)
+		t.Equal(have, 31)
+	}
+}
+
+func (t *TestSuite) TestGetIntBaseParseFailure() {
+	expected := 42
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0x1f"))
+	have, exists := GetIntBase("TEST_VAR_EXISTS", expected, 10)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntBaseNotExists() {
+	expected := 42
+	have, exists := GetIntBase("TEST_VAR_NOT_EXISTS", expected, 10)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
 func (t *TestSuite) TestGetIntInRangeExists() {
 	expected := 42
 	t.NoError(os.Setenv("TEST_VAR_EXISTS", fmt.Sprintf("%d", 42)))
@@ -105,6 +153,31 @@ func (t *TestSuite) TestGetCSVStringParseFailure() {
 	t.Equal(have, expected)
 }
 
+func (t *TestSuite) TestGetCSVStringEExists() {
+	expected := []string{"one", "two", "three"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "one,two,three"))
+	have, err := GetCSVStringE("TEST_VAR_EXISTS", nil)
+	t.NoError(err)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringENotExists() {
+	expected := []string{"default"}
+	have, err := GetCSVStringE("TEST_VAR_NOT_EXISTS", expected)
+	t.ErrorIs(err, ErrNotSet)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringEParseFailure() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `one,"tw"o`))
+	have, err := GetCSVStringE("TEST_VAR_EXISTS", expected)
+	t.ErrorIs(err, csv.ErrQuote)
+	t.False(errors.Is(err, ErrNotSet))
+	t.Contains(err.Error(), `one,\"tw\"o`)
+	t.Equal(have, expected)
+}
+
 func (t *TestSuite) TestGetStringChoicesExists() {
 	expected := "foo"
 	t.NoError(os.Setenv("TEST_VAR_EXISTS", "foo"))
@@ -127,3 +200,2579 @@ func (t *TestSuite) TestGetStringChoicesNotExists() {
 	t.False(exists)
 	t.Equal(have, expected)
 }
+
+func (t *TestSuite) TestGetStringChoicesOrFirstExists() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "baz"))
+	have, exists := GetStringChoicesOrFirst("TEST_VAR_EXISTS", []string{"foo", "bar", "baz"})
+	t.True(exists)
+	t.Equal(have, "baz")
+}
+
+func (t *TestSuite) TestGetStringChoicesOrFirstExistsBad() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "qux"))
+	have, exists := GetStringChoicesOrFirst("TEST_VAR_EXISTS", []string{"foo", "bar", "baz"})
+	t.True(exists)
+	t.Equal(have, "foo")
+}
+
+func (t *TestSuite) TestGetStringChoicesOrFirstNotExists() {
+	have, exists := GetStringChoicesOrFirst("TEST_VAR_NOT_EXISTS", []string{"foo", "bar", "baz"})
+	t.False(exists)
+	t.Equal(have, "foo")
+}
+
+func (t *TestSuite) TestGetStringChoicesOrFirstNoChoices() {
+	t.PanicsWithValue("decouple: no choices given for TEST_VAR_EXISTS", func() {
+		GetStringChoicesOrFirst("TEST_VAR_EXISTS", nil)
+	})
+}
+
+func (t *TestSuite) TestGetStringChoicesStrictValidDefault() {
+	expected := "foo"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "foo"))
+	have, exists, err := GetStringChoicesStrict("TEST_VAR_EXISTS", "bar", []string{"foo", "bar", "baz"})
+	t.NoError(err)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringChoicesStrictInvalidDefault() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "qux"))
+	have, exists, err := GetStringChoicesStrict("TEST_VAR_EXISTS", "default", []string{"foo", "bar", "baz"})
+	t.Error(err)
+	t.Contains(err.Error(), "TEST_VAR_EXISTS")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetComplex128Exists() {
+	expected := complex(1, 2)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1+2i"))
+	have, exists := GetComplex128("TEST_VAR_EXISTS", complex(0, 0))
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetComplex128RealOnly() {
+	expected := complex(3, 0)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "3"))
+	have, exists := GetComplex128("TEST_VAR_EXISTS", complex(0, 0))
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetComplex128ParseFailure() {
+	expected := complex(1, 0)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1+2j+"))
+	have, exists := GetComplex128("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetComplex128NotExists() {
+	expected := complex(1, 0)
+	have, exists := GetComplex128("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesExists() {
+	expected := int64(512 << 20)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "512MiB"))
+	have, exists := GetBytes("TEST_VAR_EXISTS", 0)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesParseFailure() {
+	expected := int64(1024)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "10 parsecs"))
+	have, exists := GetBytes("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesBoundary() {
+	for val, expected := range map[string]int64{
+		"9223372036854775807": math.MaxInt64,
+		"9007199254740993":    1<<53 + 1,
+		"8388607TiB":          8388607 << 40,
+	} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetBytes("TEST_VAR_EXISTS", 0)
+		t.True(exists, "value %q", val)
+		t.Equal(have, expected, "value %q", val)
+	}
+}
+
+func (t *TestSuite) TestGetBytesOverflow() {
+	for _, val := range []string{
+		"9223372036854775808",
+		"8388608TiB",
+		"9223372036854775808.0",
+	} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetBytes("TEST_VAR_EXISTS", 1024)
+		t.False(exists, "value %q", val)
+		t.Equal(have, int64(1024), "value %q", val)
+	}
+}
+
+func (t *TestSuite) TestGetBytesInRangeExists() {
+	expected := int64(5e9)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "5GB"))
+	have, exists := GetBytesInRange("TEST_VAR_EXISTS", 1e9, 1e6, 10e9)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesInRangeExistsMax() {
+	expected := int64(10e9)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "100GB"))
+	have, exists := GetBytesInRange("TEST_VAR_EXISTS", 1e9, 1e6, 10e9)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesInRangeExistsMin() {
+	expected := int64(1e6)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "10kb"))
+	have, exists := GetBytesInRange("TEST_VAR_EXISTS", 1e9, 1e6, 10e9)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBytesInRangeParseFailure() {
+	expected := int64(1e9)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "lots"))
+	have, exists := GetBytesInRange("TEST_VAR_EXISTS", expected, 1e6, 10e9)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringfExists() {
+	expected := "shard1.example.com"
+	t.NoError(os.Setenv("APP_SHARD_1_HOST", expected))
+	cfg := New(WithPrefix("APP_"))
+	have, exists := cfg.GetStringf("", "SHARD_%d_HOST", 1)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringfNotExists() {
+	expected := "localhost"
+	have, exists := GetStringf(expected, "TEST_VAR_NOT_EXISTS_%d", 1)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntfExists() {
+	expected := 5433
+	t.NoError(os.Setenv("APP_SHARD_2_PORT", "5433"))
+	cfg := New(WithPrefix("APP_"))
+	have, exists := cfg.GetIntf(5432, "SHARD_%d_PORT", 2)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntfNotExists() {
+	expected := 5432
+	have, exists := GetIntf(expected, "TEST_VAR_NOT_EXISTS_%d", 2)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBoolStrictTrue() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "true"))
+	have, exists := GetBoolStrict("TEST_VAR_EXISTS", false)
+	t.True(exists)
+	t.True(have)
+}
+
+func (t *TestSuite) TestGetBoolStrictFalse() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "false"))
+	have, exists := GetBoolStrict("TEST_VAR_EXISTS", true)
+	t.True(exists)
+	t.False(have)
+}
+
+func (t *TestSuite) TestGetBoolStrictNumeric() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1"))
+	have, exists := GetBoolStrict("TEST_VAR_EXISTS", false)
+	t.False(exists)
+	t.False(have)
+}
+
+func (t *TestSuite) TestGetBoolStrictWrongCase() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "True"))
+	have, exists := GetBoolStrict("TEST_VAR_EXISTS", false)
+	t.False(exists)
+	t.False(have)
+}
+
+func (t *TestSuite) TestGetBoolStrictNotExists() {
+	have, exists := GetBoolStrict("TEST_VAR_NOT_EXISTS", true)
+	t.False(exists)
+	t.True(have)
+}
+
+func (t *TestSuite) TestGetHostPortNoHost() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ":8080"))
+	host, port, raw, exists := GetHostPort("TEST_VAR_EXISTS", ":9000")
+	t.True(exists)
+	t.Equal(host, "")
+	t.Equal(port, 8080)
+	t.Equal(raw, ":8080")
+}
+
+func (t *TestSuite) TestGetHostPortWithHost() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "host:80"))
+	host, port, raw, exists := GetHostPort("TEST_VAR_EXISTS", ":9000")
+	t.True(exists)
+	t.Equal(host, "host")
+	t.Equal(port, 80)
+	t.Equal(raw, "host:80")
+}
+
+func (t *TestSuite) TestGetHostPortOutOfRange() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ":70000"))
+	host, port, raw, exists := GetHostPort("TEST_VAR_EXISTS", "localhost:9000")
+	t.False(exists)
+	t.Equal(host, "localhost")
+	t.Equal(port, 9000)
+	t.Equal(raw, "localhost:9000")
+}
+
+func (t *TestSuite) TestGetHostPortMissingColon() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "localhost"))
+	_, port, raw, exists := GetHostPort("TEST_VAR_EXISTS", ":9000")
+	t.False(exists)
+	t.Equal(port, 9000)
+	t.Equal(raw, ":9000")
+}
+
+func (t *TestSuite) TestGetHostPortNotExists() {
+	_, port, raw, exists := GetHostPort("TEST_VAR_NOT_EXISTS", ":9000")
+	t.False(exists)
+	t.Equal(port, 9000)
+	t.Equal(raw, ":9000")
+}
+
+func (t *TestSuite) TestGetPathExists() {
+	expected := filepath.Join(t.T().TempDir(), "cert.pem")
+	t.NoError(os.WriteFile(expected, []byte{}, 0o600))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetPath("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPathMissingPath() {
+	expected := "/default/path"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", filepath.Join(t.T().TempDir(), "missing")))
+	have, exists := GetPath("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPathNotExists() {
+	expected := "/default/path"
+	have, exists := GetPath("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDirExists() {
+	expected := t.T().TempDir()
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetDir("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDirNotADirectory() {
+	expected := "/default/path"
+	path := filepath.Join(t.T().TempDir(), "file")
+	t.NoError(os.WriteFile(path, []byte{}, 0o600))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", path))
+	have, exists := GetDir("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetGlobMatches() {
+	dir := t.T().TempDir()
+	expected := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+	for _, name := range []string{"b.conf", "a.conf", "c.txt"} {
+		t.NoError(os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", filepath.Join(dir, "*.conf")))
+	have, exists := GetGlob("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetGlobNoMatches() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", filepath.Join(t.T().TempDir(), "*.conf")))
+	have, exists := GetGlob("TEST_VAR_EXISTS", []string{"default"})
+	t.True(exists)
+	t.Equal(have, []string{})
+}
+
+func (t *TestSuite) TestGetGlobInvalidPattern() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "[-]"))
+	have, exists := GetGlob("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetGlobNotExists() {
+	expected := []string{"default"}
+	have, exists := GetGlob("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLogLevelExists() {
+	for val, expected := range map[string]int{
+		"debug": LogLevelDebug,
+		"info":  LogLevelInfo,
+		"warn":  LogLevelWarn,
+		"error": LogLevelError,
+	} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetLogLevel("TEST_VAR_EXISTS", -1)
+		t.True(exists)
+		t.Equal(have, expected)
+	}
+}
+
+func (t *TestSuite) TestGetLogLevelMixedCase() {
+	expected := LogLevelDebug
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "DeBuG"))
+	have, exists := GetLogLevel("TEST_VAR_EXISTS", LogLevelInfo)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLogLevelUnknown() {
+	expected := LogLevelInfo
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "verbose"))
+	have, exists := GetLogLevel("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLogLevelNotExists() {
+	expected := LogLevelInfo
+	have, exists := GetLogLevel("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestMustLoadMissingFile() {
+	envfile := filepath.Join(t.T().TempDir(), "missing.env")
+	t.PanicsWithValue(
+		fmt.Sprintf("decouple: failed to load %s: open %s: no such file or directory", envfile, envfile),
+		func() { MustLoad(envfile) },
+	)
+}
+
+func (t *TestSuite) TestMustLoadExists() {
+	envfile := filepath.Join(t.T().TempDir(), "test.env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_MUSTLOAD_VAR=loaded\n"), 0o600))
+	t.NotPanics(func() { MustLoad(envfile) })
+
+	have, exists := GetString("TEST_MUSTLOAD_VAR", "")
+	t.True(exists)
+	t.Equal(have, "loaded")
+}
+
+func (t *TestSuite) TestMustOverloadMissingFile() {
+	envfile := filepath.Join(t.T().TempDir(), "missing.env")
+	t.Panics(func() { MustOverload(envfile) })
+}
+
+func (t *TestSuite) TestGetCSVMapExists() {
+	expected := []map[string]string{
+		{"name": "a", "weight": "1"},
+		{"name": "b", "weight": "2"},
+	}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "name=a;weight=1,name=b;weight=2"))
+	have, exists := GetCSVMap("TEST_VAR_EXISTS", nil, ",", ";")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVMapParseFailure() {
+	expected := []map[string]string{{"name": "default"}}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "name=a;weight=1,name=b;weight"))
+	have, exists := GetCSVMap("TEST_VAR_EXISTS", expected, ",", ";")
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVMapNotExists() {
+	expected := []map[string]string{{"name": "default"}}
+	have, exists := GetCSVMap("TEST_VAR_NOT_EXISTS", expected, ",", ";")
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceMappedExists() {
+	expected := []string{"example.com", "example.org"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "Example.COM,example.org"))
+	have, exists := GetStringSliceMapped("TEST_VAR_EXISTS", nil, ",", func(s string) (string, error) {
+		return strings.ToLower(s), nil
+	})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceMappedTransformFailure() {
+	expected := []string{"localhost"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "example.com,,example.org"))
+	have, exists := GetStringSliceMapped("TEST_VAR_EXISTS", expected, ",", func(s string) (string, error) {
+		if s == "" {
+			return "", errors.New("empty host")
+		}
+		return s, nil
+	})
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceMappedNotExists() {
+	expected := []string{"localhost"}
+	have, exists := GetStringSliceMapped("TEST_VAR_NOT_EXISTS", expected, ",", func(s string) (string, error) {
+		return s, nil
+	})
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneExists() {
+	expected := ';'
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ";"))
+	have, exists := GetRune("TEST_VAR_EXISTS", ',')
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneMultibyte() {
+	expected := '€'
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "€"))
+	have, exists := GetRune("TEST_VAR_EXISTS", ',')
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneReplacementChar() {
+	expected := utf8.RuneError
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "�"))
+	have, exists := GetRune("TEST_VAR_EXISTS", ',')
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneInvalidUTF8() {
+	expected := ','
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "\xff"))
+	have, exists := GetRune("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneEmpty() {
+	expected := ','
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, exists := GetRune("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneTooLong() {
+	expected := ','
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "ab"))
+	have, exists := GetRune("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetRuneNotExists() {
+	expected := ','
+	have, exists := GetRune("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestReset() {
+	expected := "without prefix"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	t.NoError(os.Setenv("TEST_RESET_TEST_VAR_EXISTS", "with prefix"))
+	defer os.Unsetenv("TEST_RESET_TEST_VAR_EXISTS")
+
+	SetPrefix("TEST_RESET_")
+	AliasDeprecated("TEST_VAR_EXISTS", "TEST_VAR_NOT_EXISTS")
+	Reset()
+
+	have, exists := GetString("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+
+	_, exists = GetString("TEST_VAR_NOT_EXISTS", "")
+	t.False(exists)
+}
+
+func (t *TestSuite) TestResetFieldConverters() {
+	urlType := reflect.TypeOf(&url.URL{})
+	RegisterFieldConverter(urlType, parseTestURL)
+	Reset()
+
+	_, ok := lookupFieldConverter(urlType)
+	t.False(ok)
+}
+
+func (t *TestSuite) TestPrefixFromProgramName() {
+	t.Equal(prefixFromProgramName("/usr/local/bin/my-app"), "MY_APP_")
+	t.Equal(prefixFromProgramName("my.app+v2"), "MY_APP_V2_")
+}
+
+func (t *TestSuite) TestSetPrefixFromProgramName() {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	defer SetPrefix("")
+
+	expected := "from program name"
+	os.Args = []string{"/usr/local/bin/my-app"}
+	t.NoError(os.Setenv("MY_APP_TEST_VAR_EXISTS", expected))
+	SetPrefixFromProgramName()
+
+	have, exists := GetString("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetHexWithPrefix() {
+	expected := []byte{0xde, 0xad, 0xbe, 0xef}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0xdeadbeef"))
+	have, exists := GetHex("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetHexWithoutPrefix() {
+	expected := []byte{0xca, 0xfe}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "CAFE"))
+	have, exists := GetHex("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetHexOddLength() {
+	expected := []byte{0x00}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "abc"))
+	have, exists := GetHex("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetHexInvalid() {
+	expected := []byte{0x00}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0xdeadbeeg"))
+	have, exists := GetHex("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetHexErrorHandlerRawValue() {
+	var values []string
+	cfg := New(
+		WithEnviron(map[string]string{"KEY": "0xZZ"}),
+		WithErrorHandler(func(name, value string, err error) {
+			values = append(values, value)
+		}),
+	)
+
+	_, exists := cfg.GetHex("KEY", nil)
+	t.False(exists)
+	t.Equal(values, []string{"0xZZ"})
+}
+
+func (t *TestSuite) TestGetColorShort() {
+	expected := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "#fff"))
+	have, exists := GetColor("TEST_VAR_EXISTS", color.RGBA{})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetColorLong() {
+	expected := color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}
+	for _, val := range []string{"#1a2b3c", "1a2b3c", "#1a2b3cff"} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetColor("TEST_VAR_EXISTS", color.RGBA{})
+		t.True(exists)
+		t.Equal(have, expected)
+	}
+}
+
+func (t *TestSuite) TestGetColorAlpha() {
+	expected := color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0x80}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "#1a2b3c80"))
+	have, exists := GetColor("TEST_VAR_EXISTS", color.RGBA{})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetColorInvalid() {
+	expected := color.RGBA{A: 0xff}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "#xyz"))
+	have, exists := GetColor("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetColorNotExists() {
+	expected := color.RGBA{A: 0xff}
+	have, exists := GetColor("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntSliceInRangeExists() {
+	expected := []int{5, 100, 0}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "5,200,-3"))
+	have, exists := GetIntSliceInRange("TEST_VAR_EXISTS", nil, 0, 100)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntSliceInRangeParseFailure() {
+	expected := []int{10}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "5,many,-3"))
+	have, exists := GetIntSliceInRange("TEST_VAR_EXISTS", expected, 0, 100)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIntSliceInRangeNotExists() {
+	expected := []int{10}
+	have, exists := GetIntSliceInRange("TEST_VAR_NOT_EXISTS", expected, 0, 100)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPercentagePercent() {
+	expected := 0.25
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "25%"))
+	have, exists := GetPercentage("TEST_VAR_EXISTS", 0.1)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPercentageFraction() {
+	expected := 0.25
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0.25"))
+	have, exists := GetPercentage("TEST_VAR_EXISTS", 0.1)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPercentageClamped() {
+	expected := 1.0
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "150%"))
+	have, exists := GetPercentage("TEST_VAR_EXISTS", 0.1)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetPercentageParseFailure() {
+	expected := 0.1
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "most"))
+	have, exists := GetPercentage("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetInt64InRangeExists() {
+	expected := int64(1 << 40)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1099511627776"))
+	have, exists := GetInt64InRange("TEST_VAR_EXISTS", 0, 0, 1<<50)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetInt64InRangeExistsMax() {
+	expected := int64(1 << 40)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "9000000000000000"))
+	have, exists := GetInt64InRange("TEST_VAR_EXISTS", 0, 0, 1<<40)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetInt64InRangeExistsMin() {
+	expected := int64(-10)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "-9000000000000000"))
+	have, exists := GetInt64InRange("TEST_VAR_EXISTS", 0, -10, 1<<40)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetUintInRangeExists() {
+	expected := uint64(65536)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "65536"))
+	have, exists := GetUintInRange("TEST_VAR_EXISTS", 4096, 512, 1<<20)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetUintInRangeExistsMax() {
+	expected := uint64(1 << 20)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "18446744073709551615"))
+	have, exists := GetUintInRange("TEST_VAR_EXISTS", 4096, 512, 1<<20)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetUintInRangeExistsMin() {
+	expected := uint64(512)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1"))
+	have, exists := GetUintInRange("TEST_VAR_EXISTS", 4096, 512, 1<<20)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetUintInRangeNegative() {
+	expected := uint64(4096)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "-1"))
+	have, exists := GetUintInRange("TEST_VAR_EXISTS", expected, 512, 1<<20)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMaxLenWithinLimit() {
+	expected := "héllo"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetStringMaxLen("TEST_VAR_EXISTS", "default", 5)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMaxLenTooLong() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "héllo!"))
+	have, exists := GetStringMaxLen("TEST_VAR_EXISTS", expected, 5)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMaxLenNotExists() {
+	expected := "default"
+	have, exists := GetStringMaxLen("TEST_VAR_NOT_EXISTS", expected, 5)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringRedactedExists() {
+	expected := "s3cr3t"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, display, exists := GetStringRedacted("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+	t.Equal(display, "***")
+}
+
+func (t *TestSuite) TestGetStringRedactedNotExists() {
+	have, display, exists := GetStringRedacted("TEST_VAR_NOT_EXISTS", "")
+	t.False(exists)
+	t.Equal(have, "")
+	t.Equal(display, "")
+}
+
+func (t *TestSuite) TestGetStringOrExists() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_REGION":     "eu-central-1",
+		"APP_AWS_REGION": "eu-west-1",
+	}))
+	have, exists := cfg.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+	t.True(exists)
+	t.Equal(have, "eu-central-1")
+}
+
+func (t *TestSuite) TestGetStringOrFallback() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_AWS_REGION": "eu-west-1",
+	}))
+	have, exists := cfg.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+	t.True(exists)
+	t.Equal(have, "eu-west-1")
+}
+
+func (t *TestSuite) TestGetStringOrNotExists() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"AWS_REGION": "eu-west-1",
+	}))
+	have, exists := cfg.GetStringOr("REGION", "AWS_REGION", "us-east-1")
+	t.False(exists)
+	t.Equal(have, "us-east-1")
+}
+
+func (t *TestSuite) TestGetAllStrings() {
+	cfg := New(WithEnviron(map[string]string{
+		"DB_HOST": "db.example.com",
+		"DB_USER": "app",
+	}))
+	values, exists := cfg.GetAllStrings(
+		[]string{"DB_HOST", "DB_USER", "DB_PORT", "DB_NAME"},
+		map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432"},
+	)
+	t.Equal(values, map[string]string{
+		"DB_HOST": "db.example.com",
+		"DB_USER": "app",
+		"DB_PORT": "5432",
+		"DB_NAME": "",
+	})
+	t.Equal(exists, map[string]bool{
+		"DB_HOST": true,
+		"DB_USER": true,
+		"DB_PORT": false,
+		"DB_NAME": false,
+	})
+}
+
+func (t *TestSuite) TestGetTimeUnixExists() {
+	expected := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1700000000"))
+	have, exists := GetTimeUnix("TEST_VAR_EXISTS", time.Time{})
+	t.True(exists)
+	t.True(have.Equal(expected))
+}
+
+func (t *TestSuite) TestGetTimeUnixNegative() {
+	expected := time.Date(1969, 12, 31, 23, 59, 0, 0, time.UTC)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "-60"))
+	have, exists := GetTimeUnix("TEST_VAR_EXISTS", time.Time{})
+	t.True(exists)
+	t.True(have.Equal(expected))
+}
+
+func (t *TestSuite) TestGetTimeUnixParseFailure() {
+	expected := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "yesterday"))
+	have, exists := GetTimeUnix("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetTimeUnixMilliExists() {
+	expected := time.Date(2023, 11, 14, 22, 13, 20, 123000000, time.UTC)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1700000000123"))
+	have, exists := GetTimeUnixMilli("TEST_VAR_EXISTS", time.Time{})
+	t.True(exists)
+	t.True(have.Equal(expected))
+}
+
+func (t *TestSuite) TestGetStringRequiredExists() {
+	expected := "postgres://localhost/db"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, err := GetStringRequired("TEST_VAR_EXISTS")
+	t.NoError(err)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringRequiredNotExists() {
+	_, err := GetStringRequired("TEST_VAR_NOT_EXISTS")
+	t.ErrorIs(err, ErrNotSet)
+	t.Contains(err.Error(), "TEST_VAR_NOT_EXISTS")
+}
+
+func checkNoSpaces(s string) error {
+	if strings.Contains(s, " ") {
+		return errors.New("must not contain spaces")
+	}
+	return nil
+}
+
+func (t *TestSuite) TestGetStringCheckedValid() {
+	expected := "us-east-1"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, err := GetStringChecked("TEST_VAR_EXISTS", "default", checkNoSpaces)
+	t.NoError(err)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringCheckedInvalid() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "us east 1"))
+	have, err := GetStringChecked("TEST_VAR_EXISTS", expected, checkNoSpaces)
+	t.Error(err)
+	t.Contains(err.Error(), "TEST_VAR_EXISTS")
+	t.Contains(err.Error(), "must not contain spaces")
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringCheckedNotExists() {
+	expected := "default"
+	have, err := GetStringChecked("TEST_VAR_NOT_EXISTS", expected, checkNoSpaces)
+	t.ErrorIs(err, ErrNotSet)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMustfExists() {
+	expected := "postgres://localhost/db"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	t.Equal(GetStringMustf("TEST_VAR_EXISTS", "not set"), expected)
+}
+
+func (t *TestSuite) TestGetStringMustfNotExists() {
+	t.PanicsWithValue("set DB_URL to your Postgres DSN (see 42)", func() {
+		GetStringMustf("TEST_VAR_NOT_EXISTS", "set DB_URL to your Postgres DSN (see %d)", 42)
+	})
+}
+
+func (t *TestSuite) TestGetStringMatchingExists() {
+	expected := "v1.2.3"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetStringMatching("TEST_VAR_EXISTS", "v0.0.0", regexp.MustCompile(`^v\d+\.\d+\.\d+$`))
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMatchingExistsBad() {
+	expected := "v0.0.0"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "v1.2.3-extra"))
+	have, exists := GetStringMatching("TEST_VAR_EXISTS", expected, regexp.MustCompile(`v\d+\.\d+\.\d+`))
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMatchingAlternation() {
+	expected := "1.2"
+	pattern := regexp.MustCompile(`\d+|\d+\.\d+`)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetStringMatching("TEST_VAR_EXISTS", "0", pattern)
+	t.True(exists)
+	t.Equal(have, expected)
+
+	have, exists = GetStringMatching("TEST_VAR_EXISTS", "0", pattern)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringMatchingNotExists() {
+	expected := "v0.0.0"
+	have, exists := GetStringMatching("TEST_VAR_NOT_EXISTS", expected, regexp.MustCompile(`v\d+\.\d+\.\d+`))
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceUniqueExists() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,a,c"))
+	have, exists := GetStringSliceUnique("TEST_VAR_EXISTS", nil, ",")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceUniquePreservesOrder() {
+	expected := []string{"c", "a", "b"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", " c , a,c,b ,a"))
+	have, exists := GetStringSliceUnique("TEST_VAR_EXISTS", nil, ",")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceUniqueAllEmpty() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ", ,,"))
+	have, exists := GetStringSliceUnique("TEST_VAR_EXISTS", []string{"default"}, ",")
+	t.True(exists)
+	t.Empty(have)
+}
+
+func (t *TestSuite) TestGetStringSliceUniqueNotExists() {
+	expected := []string{"default"}
+	have, exists := GetStringSliceUnique("TEST_VAR_NOT_EXISTS", expected, ",")
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetTokensMixedSeparators() {
+	expected := []string{"a", "b", "c", "d"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a, b ,c\td"))
+	have, exists := GetTokens("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetTokensLeadingTrailingSeparators() {
+	expected := []string{"a", "b"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", " ,a,,b, "))
+	have, exists := GetTokens("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetTokensSingle() {
+	expected := []string{"a"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a"))
+	have, exists := GetTokens("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetTokensNotExists() {
+	expected := []string{"default"}
+	have, exists := GetTokens("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetArgvPlain() {
+	expected := []string{"--verbose", "-n", "3"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", " --verbose  -n 3 "))
+	have, exists := GetArgv("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetArgvQuoted() {
+	expected := []string{"--label", "hello world", "it's", "", `say "hi"`}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `--label "hello world" "it's" '' 'say "hi"'`))
+	have, exists := GetArgv("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetArgvEscaped() {
+	expected := []string{"hello world", `a"b`, `c\d`}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `hello\ world "a\"b" c\\d`))
+	have, exists := GetArgv("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetArgvUnbalancedQuote() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `--label "hello world`))
+	have, exists := GetArgv("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetArgvListOptions() {
+	cfg := New(
+		WithSliceElementTransform(strings.ToUpper),
+		WithDropEmptyListElements(true),
+		WithEnviron(map[string]string{"ARGS": `-v "" 'a b'`}),
+	)
+
+	have, exists := cfg.GetArgv("ARGS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"-V", "A B"})
+}
+
+func (t *TestSuite) TestGetArgvNotExists() {
+	expected := []string{"default"}
+	have, exists := GetArgv("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+var testFeatures = []string{"search", "export", "beta"}
+
+func (t *TestSuite) TestGetStringSliceChoicesMaxExists() {
+	expected := []string{"search", "export"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "search,export"))
+	have, exists := GetStringSliceChoicesMax("TEST_VAR_EXISTS", nil, testFeatures, 2)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceChoicesMaxTooMany() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "search,search,search"))
+	have, exists := GetStringSliceChoicesMax("TEST_VAR_EXISTS", expected, testFeatures, 2)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceChoicesMaxInvalidChoice() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "search,import"))
+	have, exists := GetStringSliceChoicesMax("TEST_VAR_EXISTS", expected, testFeatures, 2)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSliceChoicesMaxNotExists() {
+	expected := []string{"default"}
+	have, exists := GetStringSliceChoicesMax("TEST_VAR_NOT_EXISTS", expected, testFeatures, 2)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+type testMode int
+
+func parseTestMode(s string) (testMode, error) {
+	i, err := strconv.Atoi(s)
+	return testMode(i), err
+}
+
+func (t *TestSuite) TestGetChoiceIndexMatch() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "replica"))
+	have, exists := GetChoiceIndex("TEST_VAR_EXISTS", -1, []string{"primary", "replica", "witness"})
+	t.True(exists)
+	t.Equal(have, 1)
+}
+
+func (t *TestSuite) TestGetChoiceIndexNoMatch() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "Replica"))
+	have, exists := GetChoiceIndex("TEST_VAR_EXISTS", -1, []string{"primary", "replica", "witness"})
+	t.True(exists)
+	t.Equal(have, -1)
+}
+
+func (t *TestSuite) TestGetChoiceIndexNotExists() {
+	have, exists := GetChoiceIndex("TEST_VAR_NOT_EXISTS", -1, []string{"primary", "replica", "witness"})
+	t.False(exists)
+	t.Equal(have, -1)
+}
+
+var testSizes = []string{"small", "medium", "large"}
+
+func (t *TestSuite) TestGetChoiceByNameOrIndexName() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "medium"))
+	have, exists := GetChoiceByNameOrIndex("TEST_VAR_EXISTS", "small", testSizes)
+	t.True(exists)
+	t.Equal(have, "medium")
+}
+
+func (t *TestSuite) TestGetChoiceByNameOrIndexIndex() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "3"))
+	have, exists := GetChoiceByNameOrIndex("TEST_VAR_EXISTS", "small", testSizes)
+	t.True(exists)
+	t.Equal(have, "large")
+}
+
+func (t *TestSuite) TestGetChoiceByNameOrIndexOutOfRange() {
+	for _, val := range []string{"0", "4"} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetChoiceByNameOrIndex("TEST_VAR_EXISTS", "small", testSizes)
+		t.True(exists)
+		t.Equal(have, "small")
+	}
+}
+
+func (t *TestSuite) TestGetChoiceByNameOrIndexNotExists() {
+	have, exists := GetChoiceByNameOrIndex("TEST_VAR_NOT_EXISTS", "small", testSizes)
+	t.False(exists)
+	t.Equal(have, "small")
+}
+
+var testEnvironmentAliases = map[string]string{
+	"prod": "production",
+	"dev":  "development",
+}
+
+func (t *TestSuite) TestGetStringAliasedCanonical() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "production"))
+	have, exists := GetStringAliased("TEST_VAR_EXISTS", "development", testEnvironmentAliases)
+	t.True(exists)
+	t.Equal(have, "production")
+}
+
+func (t *TestSuite) TestGetStringAliasedAlias() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "prod"))
+	have, exists := GetStringAliased("TEST_VAR_EXISTS", "development", testEnvironmentAliases)
+	t.True(exists)
+	t.Equal(have, "production")
+}
+
+func (t *TestSuite) TestGetStringAliasedUnknown() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "staging"))
+	have, exists := GetStringAliased("TEST_VAR_EXISTS", "development", testEnvironmentAliases)
+	t.True(exists)
+	t.Equal(have, "development")
+}
+
+func (t *TestSuite) TestGetStringAliasedNotExists() {
+	have, exists := GetStringAliased("TEST_VAR_NOT_EXISTS", "development", testEnvironmentAliases)
+	t.False(exists)
+	t.Equal(have, "development")
+}
+
+func (t *TestSuite) TestGetOneOfExists() {
+	expected := testMode(2)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "2"))
+	have, exists := GetOneOf("TEST_VAR_EXISTS", testMode(1), parseTestMode, []testMode{1, 2, 3})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetOneOfExistsBad() {
+	expected := testMode(1)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "4"))
+	have, exists := GetOneOf("TEST_VAR_EXISTS", expected, parseTestMode, []testMode{1, 2, 3})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetOneOfParseFailure() {
+	expected := testMode(1)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "two"))
+	have, exists := GetOneOf("TEST_VAR_EXISTS", expected, parseTestMode, []testMode{1, 2, 3})
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func parseInt(s string) (int, error) {
+	i, err := strconv.ParseInt(s, 0, 0)
+	return int(i), err
+}
+
+func (t *TestSuite) TestGetParsedExists() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0x10"))
+	have, exists := GetParsed("TEST_VAR_EXISTS", 0, parseInt)
+	t.True(exists)
+	t.Equal(have, 16)
+}
+
+func (t *TestSuite) TestGetParsedParseFailure() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "sixteen"))
+	have, exists := GetParsed("TEST_VAR_EXISTS", 42, parseInt)
+	t.False(exists)
+	t.Equal(have, 42)
+}
+
+func (t *TestSuite) TestGetParsedNotExists() {
+	have, exists := GetParsed("TEST_VAR_NOT_EXISTS", 42, parseInt)
+	t.False(exists)
+	t.Equal(have, 42)
+}
+
+func (t *TestSuite) TestGetParsedMatchesGetInt() {
+	for _, val := range []string{"10", "-3", "010", "0x1f", "ten", ""} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetParsed("TEST_VAR_EXISTS", 42, parseInt)
+		expected, expectedExists := GetInt("TEST_VAR_EXISTS", 42)
+		t.Equal(exists, expectedExists, "value %q", val)
+		t.Equal(have, expected, "value %q", val)
+	}
+}
+
+type testColor int
+
+const (
+	testColorRed testColor = iota
+	testColorGreen
+	testColorBlue
+)
+
+func (c testColor) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+var testColors = []testColor{testColorRed, testColorGreen, testColorBlue}
+
+func (t *TestSuite) TestGetEnumStringerExists() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "green"))
+	have, exists := GetEnumStringer("TEST_VAR_EXISTS", testColorRed, testColors)
+	t.True(exists)
+	t.Equal(have, testColorGreen)
+}
+
+func (t *TestSuite) TestGetEnumStringerExistsBad() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "purple"))
+	have, exists := GetEnumStringer("TEST_VAR_EXISTS", testColorRed, testColors)
+	t.True(exists)
+	t.Equal(have, testColorRed)
+}
+
+func (t *TestSuite) TestGetEnumStringerNotExists() {
+	have, exists := GetEnumStringer("TEST_VAR_NOT_EXISTS", testColorBlue, testColors)
+	t.False(exists)
+	t.Equal(have, testColorBlue)
+}
+
+func (t *TestSuite) TestGetStringExpandedExists() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_HOST": "example.com",
+		"APP_URL":  "https://${HOST}/",
+	}))
+	have, exists := cfg.GetStringExpanded("URL", "")
+	t.True(exists)
+	t.Equal(have, "https://example.com/")
+}
+
+func (t *TestSuite) TestGetStringExpandedUnknownReference() {
+	cfg := New(WithEnviron(map[string]string{
+		"URL":  "https://${HOST}:${PORT}/",
+		"PORT": "8443",
+	}))
+	have, exists := cfg.GetStringExpanded("URL", "")
+	t.True(exists)
+	t.Equal(have, "https://:8443/")
+}
+
+func (t *TestSuite) TestGetStringExpandedEscaped() {
+	cfg := New(WithEnviron(map[string]string{
+		"PRICE":    "$$5 (${CURRENCY})",
+		"CURRENCY": "USD",
+	}))
+	have, exists := cfg.GetStringExpanded("PRICE", "")
+	t.True(exists)
+	t.Equal(have, "$5 (USD)")
+}
+
+func (t *TestSuite) TestGetStringExpandedNotExists() {
+	expected := "${HOST}"
+	have, exists := GetStringExpanded("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapExists() {
+	expected := map[string]string{"env": "prod", "team": "core"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "env=prod,team=core"))
+	have, exists := GetMap("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapParseFailure() {
+	expected := map[string]string{"env": "dev"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "env=prod,team"))
+	have, exists := GetMap("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapFlexibleJSON() {
+	expected := map[string]string{"a": "1", "b": "x=y"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ` {"a": "1", "b": "x=y"}`))
+	have, exists := GetMapFlexible("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapFlexiblePairs() {
+	expected := map[string]string{"a": "1", "b": "2"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a=1,b=2"))
+	have, exists := GetMapFlexible("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapFlexibleInvalidJSON() {
+	expected := map[string]string{"a": "0"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `{"a": 1}`))
+	have, exists := GetMapFlexible("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapFlexibleNotExists() {
+	expected := map[string]string{"a": "0"}
+	have, exists := GetMapFlexible("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMultiMapExists() {
+	expected := map[string][]string{"role": {"admin", "editor"}, "team": {"core"}}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "role=admin,team=core,role=editor"))
+	have, exists := GetMultiMap("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMultiMapParseFailure() {
+	expected := map[string][]string{"role": {"viewer"}}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "role=admin,team"))
+	have, exists := GetMultiMap("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMultiMapNotExists() {
+	expected := map[string][]string{"role": {"viewer"}}
+	have, exists := GetMultiMap("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetWeightedChoicesExists() {
+	expected := map[string]int{"a": 3, "b": 1, "c": 0}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a:3,b:1,c:0"))
+	have, exists := GetWeightedChoices("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetWeightedChoicesMissingWeight() {
+	expected := map[string]int{"a": 1}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a:3,b"))
+	have, exists := GetWeightedChoices("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetWeightedChoicesNegativeWeight() {
+	expected := map[string]int{"a": 1}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a:3,b:-1"))
+	have, exists := GetWeightedChoices("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetWeightedChoicesNotExists() {
+	expected := map[string]int{"a": 1}
+	have, exists := GetWeightedChoices("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapTypedExists() {
+	expected := map[string]int{"k1": 1, "k2": 2}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "k1=1,k2=2"))
+	have, exists := GetMapTyped("TEST_VAR_EXISTS", nil, strconv.Atoi)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapTypedConversionFailure() {
+	expected := map[string]int{"k1": 0}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "k1=1,k2=two"))
+	have, exists := GetMapTyped("TEST_VAR_EXISTS", expected, strconv.Atoi)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetMapTypedNotExists() {
+	expected := map[string]int{"k1": 0}
+	have, exists := GetMapTyped("TEST_VAR_NOT_EXISTS", expected, strconv.Atoi)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestLoadLayered() {
+	dir := t.T().TempDir()
+	base := filepath.Join(dir, ".env")
+	production := filepath.Join(dir, ".env.production")
+	t.NoError(os.WriteFile(base, []byte("TEST_LAYERED_A=base\nTEST_LAYERED_B=base\n"), 0o600))
+	t.NoError(os.WriteFile(production, []byte("TEST_LAYERED_B=production\n"), 0o600))
+
+	t.NoError(LoadLayered(base, filepath.Join(dir, ".env.missing"), production))
+
+	have, _ := GetString("TEST_LAYERED_A", "")
+	t.Equal(have, "base")
+	have, _ = GetString("TEST_LAYERED_B", "")
+	t.Equal(have, "production")
+}
+
+func (t *TestSuite) TestLoadIfExists() {
+	dir := t.T().TempDir()
+	local := filepath.Join(dir, ".env.local")
+	t.NoError(os.WriteFile(local, []byte("TEST_IFEXISTS_VAR=local\n"), 0o600))
+	defer os.Unsetenv("TEST_IFEXISTS_VAR")
+
+	t.NoError(LoadIfExists(filepath.Join(dir, ".env.missing"), local))
+
+	have, exists := GetString("TEST_IFEXISTS_VAR", "")
+	t.True(exists)
+	t.Equal(have, "local")
+}
+
+func (t *TestSuite) TestLoadIfExistsMissing() {
+	t.NoError(LoadIfExists(filepath.Join(t.T().TempDir(), ".env.missing")))
+}
+
+func (t *TestSuite) TestLoadIfExistsMalformed() {
+	envfile := filepath.Join(t.T().TempDir(), ".env")
+	t.NoError(os.WriteFile(envfile, []byte("this is not a valid line\n"), 0o600))
+	t.Error(LoadIfExists(envfile))
+}
+
+func (t *TestSuite) TestLoadFirst() {
+	dir := t.T().TempDir()
+	first := filepath.Join(dir, "first.env")
+	second := filepath.Join(dir, "second.env")
+	t.NoError(os.WriteFile(first, []byte("TEST_LOADFIRST_VAR=first\n"), 0o600))
+	t.NoError(os.WriteFile(second, []byte("TEST_LOADFIRST_VAR=second\n"), 0o600))
+	defer os.Unsetenv("TEST_LOADFIRST_VAR")
+
+	filename, err := LoadFirst(first, second)
+	t.NoError(err)
+	t.Equal(filename, first)
+
+	have, _ := GetString("TEST_LOADFIRST_VAR", "")
+	t.Equal(have, "first")
+}
+
+func (t *TestSuite) TestLoadFirstSkipsMissing() {
+	dir := t.T().TempDir()
+	second := filepath.Join(dir, "second.env")
+	t.NoError(os.WriteFile(second, []byte("TEST_LOADFIRST_VAR=second\n"), 0o600))
+	defer os.Unsetenv("TEST_LOADFIRST_VAR")
+
+	filename, err := LoadFirst(filepath.Join(dir, "first.env"), second)
+	t.NoError(err)
+	t.Equal(filename, second)
+
+	have, _ := GetString("TEST_LOADFIRST_VAR", "")
+	t.Equal(have, "second")
+}
+
+func (t *TestSuite) TestLoadFirstNoneExist() {
+	dir := t.T().TempDir()
+	first := filepath.Join(dir, "first.env")
+	second := filepath.Join(dir, "second.env")
+
+	_, err := LoadFirst(first, second)
+	t.Error(err)
+	t.Contains(err.Error(), first)
+	t.Contains(err.Error(), second)
+}
+
+func (t *TestSuite) TestSourceFromFile() {
+	envfile := filepath.Join(t.T().TempDir(), "test.env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_SOURCE_VAR=loaded\n"), 0o600))
+	defer os.Unsetenv("TEST_SOURCE_VAR")
+	t.NoError(Load(envfile))
+
+	have, origin, exists := Source("TEST_SOURCE_VAR")
+	t.True(exists)
+	t.Equal(have, "loaded")
+	t.Equal(origin, envfile)
+}
+
+func (t *TestSuite) TestSourceFromEnvironment() {
+	envfile := filepath.Join(t.T().TempDir(), "test.env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_VAR_EXISTS=loaded\n"), 0o600))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "ambient"))
+	t.NoError(Load(envfile))
+
+	have, origin, exists := Source("TEST_VAR_EXISTS")
+	t.True(exists)
+	t.Equal(have, "ambient")
+	t.Equal(origin, "os")
+}
+
+func (t *TestSuite) TestSourceAfterReset() {
+	envfile := filepath.Join(t.T().TempDir(), "test.env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_SOURCE_VAR=loaded\n"), 0o600))
+	defer os.Unsetenv("TEST_SOURCE_VAR")
+	t.NoError(Load(envfile))
+	Reset()
+
+	have, origin, exists := Source("TEST_SOURCE_VAR")
+	t.True(exists)
+	t.Equal(have, "loaded")
+	t.Equal(origin, "os")
+}
+
+func (t *TestSuite) TestSourceNotExists() {
+	have, origin, exists := Source("TEST_VAR_NOT_EXISTS")
+	t.False(exists)
+	t.Equal(have, "")
+	t.Equal(origin, "")
+}
+
+func (t *TestSuite) TestGetStringNonEmptyExists() {
+	expected := "production"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", expected))
+	have, exists := GetStringNonEmpty("TEST_VAR_EXISTS", "default")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringNonEmptyEmpty() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, exists := GetStringNonEmpty("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringNonEmptyWhitespace() {
+	expected := "default"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", " \t "))
+	have, exists := GetStringNonEmpty("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringNonEmptyNotExists() {
+	expected := "default"
+	have, exists := GetStringNonEmpty("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringBoundedExists() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c"))
+	have, exists := GetCSVStringBounded("TEST_VAR_EXISTS", nil, 1, 5)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringBoundedTooFew() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a"))
+	have, exists := GetCSVStringBounded("TEST_VAR_EXISTS", expected, 2, 5)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringBoundedTooMany() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c,d,e,f"))
+	have, exists := GetCSVStringBounded("TEST_VAR_EXISTS", expected, 1, 5)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringBoundedNotExists() {
+	expected := []string{"default"}
+	have, exists := GetCSVStringBounded("TEST_VAR_NOT_EXISTS", expected, 1, 5)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetSemverExists() {
+	expected := Version{1, 4, 2, ""}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1.4.2"))
+	have, exists := GetSemver("TEST_VAR_EXISTS", Version{1, 0, 0, ""})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetSemverPreRelease() {
+	expected := Version{2, 0, 0, "rc1"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "2.0.0-rc1"))
+	have, exists := GetSemver("TEST_VAR_EXISTS", Version{1, 0, 0, ""})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetSemverParseFailure() {
+	expected := Version{1, 0, 0, ""}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1.x"))
+	have, exists := GetSemver("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetSemverNotExists() {
+	expected := Version{1, 0, 0, ""}
+	have, exists := GetSemver("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringChoicesFoldExists() {
+	expected := "small"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "SMALL"))
+	have, exists := GetStringChoicesFold("TEST_VAR_EXISTS", "medium", []string{"small", "medium", "large"})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringChoicesFoldCanonical() {
+	expected := "Large"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "lArGe"))
+	have, exists := GetStringChoicesFold("TEST_VAR_EXISTS", "medium", []string{"small", "medium", "Large"})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringChoicesFoldExistsBad() {
+	expected := "medium"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "huge"))
+	have, exists := GetStringChoicesFold("TEST_VAR_EXISTS", expected, []string{"small", "medium", "large"})
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringChoicesFoldNotExists() {
+	expected := "medium"
+	have, exists := GetStringChoicesFold("TEST_VAR_NOT_EXISTS", expected, []string{"small", "medium", "large"})
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+type testRule struct {
+	Path  string `json:"path"`
+	Allow bool   `json:"allow"`
+}
+
+func (t *TestSuite) TestGetJSONIntoExists() {
+	expected := []testRule{{"/admin", false}, {"/", true}}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `[{"path": "/admin"}, {"path": "/", "allow": true}]`))
+	var have []testRule
+	exists, err := GetJSONInto("TEST_VAR_EXISTS", &have)
+	t.NoError(err)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetJSONIntoSyntaxError() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `[{"path": "/admin"`))
+	var have []testRule
+	exists, err := GetJSONInto("TEST_VAR_EXISTS", &have)
+	t.Error(err)
+	t.False(exists)
+}
+
+func (t *TestSuite) TestGetJSONIntoTypeMismatch() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `{"path": "/admin"}`))
+	var have []testRule
+	exists, err := GetJSONInto("TEST_VAR_EXISTS", &have)
+	t.Error(err)
+	t.False(exists)
+}
+
+func (t *TestSuite) TestGetJSONIntoNotExists() {
+	var have []testRule
+	exists, err := GetJSONInto("TEST_VAR_NOT_EXISTS", &have)
+	t.NoError(err)
+	t.False(exists)
+	t.Nil(have)
+}
+
+var testUUID = [16]byte{
+	0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3,
+	0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00,
+}
+
+func (t *TestSuite) TestGetUUIDExists() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "123e4567-e89b-12d3-a456-426614174000"))
+	have, exists := GetUUID("TEST_VAR_EXISTS", [16]byte{})
+	t.True(exists)
+	t.Equal(have, testUUID)
+}
+
+func (t *TestSuite) TestGetUUIDUpperCase() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "123E4567-E89B-12D3-A456-426614174000"))
+	have, exists := GetUUID("TEST_VAR_EXISTS", [16]byte{})
+	t.True(exists)
+	t.Equal(have, testUUID)
+}
+
+func (t *TestSuite) TestGetUUIDParseFailure() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "123e4567-e89b-12d3-426614174000"))
+	have, exists := GetUUID("TEST_VAR_EXISTS", [16]byte{})
+	t.False(exists)
+	t.Equal(have, [16]byte{})
+}
+
+func (t *TestSuite) TestGetUUIDNotExists() {
+	have, exists := GetUUID("TEST_VAR_NOT_EXISTS", testUUID)
+	t.False(exists)
+	t.Equal(have, testUUID)
+}
+
+func (t *TestSuite) TestGetJSONStringSliceExists() {
+	expected := []string{"a,b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `["a,b", "c"]`))
+	have, exists := GetJSONStringSlice("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetJSONStringSliceObject() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `{"a": "b"}`))
+	have, exists := GetJSONStringSlice("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetJSONStringSliceNumbers() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `[1, 2, 3]`))
+	have, exists := GetJSONStringSlice("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetJSONStringSliceNotExists() {
+	expected := []string{"default"}
+	have, exists := GetJSONStringSlice("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringValidatedExists() {
+	expected := []string{"example", "internal"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "example,internal"))
+	have, exists := GetCSVStringValidated("TEST_VAR_EXISTS", nil, checkNoSpaces)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringValidatedInvalid() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "example,not valid"))
+	have, exists := GetCSVStringValidated("TEST_VAR_EXISTS", expected, checkNoSpaces)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringValidatedEmpty() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, exists := GetCSVStringValidated("TEST_VAR_EXISTS", expected, checkNoSpaces)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringValidatedNotExists() {
+	expected := []string{"default"}
+	have, exists := GetCSVStringValidated("TEST_VAR_NOT_EXISTS", expected, checkNoSpaces)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringCountExists() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c"))
+	have, count, exists := GetCSVStringCount("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+	t.Equal(count, 3)
+}
+
+func (t *TestSuite) TestGetCSVStringCountEmpty() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, count, exists := GetCSVStringCount("TEST_VAR_EXISTS", []string{"default"})
+	t.True(exists)
+	t.Equal(have, []string{})
+	t.Equal(count, 0)
+}
+
+func (t *TestSuite) TestGetCSVStringCountNotExists() {
+	expected := []string{"x", "y"}
+	have, count, exists := GetCSVStringCount("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+	t.Equal(count, 2)
+}
+
+func (t *TestSuite) TestGetCSVStringSortedExists() {
+	expected := []string{"alice", "bob", "carol"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "carol, alice,bob,alice ,carol"))
+	have, exists := GetCSVStringSorted("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringSortedEmpty() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, exists := GetCSVStringSorted("TEST_VAR_EXISTS", []string{"default"})
+	t.True(exists)
+	t.Equal(have, []string{})
+}
+
+func (t *TestSuite) TestGetCSVStringSortedNotExists() {
+	expected := []string{"default"}
+	have, exists := GetCSVStringSorted("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSetExists() {
+	expected := map[string]struct{}{"a": {}, "b": {}, "c": {}}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c,a"))
+	have, exists := GetStringSet("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringSetNotExists() {
+	expected := map[string]struct{}{"x": {}, "y": {}}
+	have, exists := GetStringSet("TEST_VAR_NOT_EXISTS", []string{"x", "y", "x"})
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetIndexedStringsExists() {
+	cfg := New(WithEnviron(map[string]string{
+		"SERVER_0": "s0",
+		"SERVER_1": "s1",
+	}))
+	have, exists := cfg.GetIndexedStrings("SERVER", nil)
+	t.True(exists)
+	t.Equal(have, []string{"s0", "s1"})
+}
+
+func (t *TestSuite) TestGetIndexedStringsGap() {
+	cfg := New(WithEnviron(map[string]string{
+		"SERVER_0": "s0",
+		"SERVER_2": "s2",
+	}))
+	have, exists := cfg.GetIndexedStrings("SERVER", nil)
+	t.True(exists)
+	t.Equal(have, []string{"s0"})
+}
+
+func (t *TestSuite) TestGetIndexedStringsNotExists() {
+	expected := []string{"default"}
+	cfg := New(WithEnviron(map[string]string{
+		"SERVER_1": "s1",
+	}))
+	have, exists := cfg.GetIndexedStrings("SERVER", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetBoolStringExists() {
+	for val, expected := range map[string]string{
+		"yes":  "true",
+		"ON":   "true",
+		"1":    "true",
+		"0":    "false",
+		"No":   "false",
+		"off":  "false",
+		"true": "true",
+	} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetBoolString("TEST_VAR_EXISTS", false)
+		t.True(exists)
+		t.Equal(have, expected, "value %q", val)
+	}
+}
+
+func (t *TestSuite) TestGetBoolStringParseFailure() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "sometimes"))
+	have, exists := GetBoolString("TEST_VAR_EXISTS", true)
+	t.False(exists)
+	t.Equal(have, "true")
+}
+
+func (t *TestSuite) TestGetBoolStringNotExists() {
+	have, exists := GetBoolString("TEST_VAR_NOT_EXISTS", false)
+	t.False(exists)
+	t.Equal(have, "false")
+}
+
+func (t *TestSuite) TestGetBoolSourceExists() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "T"))
+	have, raw, fromEnv := GetBoolSource("TEST_VAR_EXISTS", false)
+	t.True(fromEnv)
+	t.True(have)
+	t.Equal(raw, "T")
+}
+
+func (t *TestSuite) TestGetBoolSourceParseFailure() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "maybe"))
+	have, raw, fromEnv := GetBoolSource("TEST_VAR_EXISTS", true)
+	t.False(fromEnv)
+	t.True(have)
+	t.Equal(raw, "maybe")
+}
+
+func (t *TestSuite) TestGetBoolSourceNotExists() {
+	have, raw, fromEnv := GetBoolSource("TEST_VAR_NOT_EXISTS", true)
+	t.False(fromEnv)
+	t.True(have)
+	t.Equal(raw, "")
+}
+
+func (t *TestSuite) TestGetListJSON() {
+	expected := []string{"a", "b,c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ` ["a", "b,c"]`))
+	have, exists := GetList("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetListCSV() {
+	expected := []string{"a", "b"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b"))
+	have, exists := GetList("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetListInvalidJSON() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `["a", "b"`))
+	have, exists := GetList("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetListNotExists() {
+	expected := []string{"default"}
+	have, exists := GetList("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationExists() {
+	expected := 30 * time.Second
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "30s"))
+	have, exists := GetDuration("TEST_VAR_EXISTS", 0)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationInRangeExists() {
+	expected := 30 * time.Second
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "30s"))
+	have, exists := GetDurationInRange("TEST_VAR_EXISTS", 10*time.Second, time.Second, 5*time.Minute)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationInRangeExistsMin() {
+	expected := time.Second
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0s"))
+	have, exists := GetDurationInRange("TEST_VAR_EXISTS", 10*time.Second, time.Second, 5*time.Minute)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationInRangeExistsMax() {
+	expected := 5 * time.Minute
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "10h"))
+	have, exists := GetDurationInRange("TEST_VAR_EXISTS", 10*time.Second, time.Second, 5*time.Minute)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationInRangeParseFailure() {
+	expected := 10 * time.Second
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a while"))
+	have, exists := GetDurationInRange("TEST_VAR_EXISTS", expected, time.Second, 5*time.Minute)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationSliceExists() {
+	expected := []time.Duration{100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "100ms, 500ms,2s"))
+	have, exists := GetDurationSlice("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationSliceParseFailure() {
+	expected := []time.Duration{time.Second}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "100ms,soon,2s"))
+	have, exists := GetDurationSlice("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationSliceEmpty() {
+	expected := []time.Duration{time.Second}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", ""))
+	have, exists := GetDurationSlice("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetDurationSliceNotExists() {
+	expected := []time.Duration{time.Second}
+	have, exists := GetDurationSlice("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringTemplateExists() {
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_HOST": "db.example.com",
+		"APP_PORT": "5432",
+		"APP_DSN":  `{{.HOST}}:{{.PORT}}/{{env "NAME"}}`,
+		"APP_NAME": "app",
+	}))
+	have, exists := cfg.GetStringTemplate("DSN", "")
+	t.True(exists)
+	t.Equal(have, "db.example.com:5432/app")
+}
+
+func (t *TestSuite) TestGetStringTemplateMissingKey() {
+	expected := "localhost:5432"
+	cfg := New(WithEnviron(map[string]string{
+		"DSN": "{{.HOST}}:{{.PORT}}",
+	}))
+	have, exists := cfg.GetStringTemplate("DSN", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringTemplateParseFailure() {
+	expected := "localhost:5432"
+	cfg := New(WithEnviron(map[string]string{
+		"DSN": "{{.HOST",
+	}))
+	have, exists := cfg.GetStringTemplate("DSN", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringTemplateNotExists() {
+	expected := "localhost:5432"
+	have, exists := GetStringTemplate("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringUnescapedNewline() {
+	expected := "-----BEGIN KEY-----\nabc\n-----END KEY-----"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `-----BEGIN KEY-----\nabc\n-----END KEY-----`))
+	have, exists := GetStringUnescaped("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringUnescapedTab() {
+	expected := "\t\"quoted\" \\ \xff é"
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `\t"quoted" \\ \xff é`))
+	have, exists := GetStringUnescaped("TEST_VAR_EXISTS", "")
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringUnescapedParseFailure() {
+	expected := ","
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `\q`))
+	have, exists := GetStringUnescaped("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetStringUnescapedNotExists() {
+	expected := ","
+	have, exists := GetStringUnescaped("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetListFlexibleCSV() {
+	cfg := New(WithEnviron(map[string]string{
+		"SERVERS":   "a,b",
+		"SERVERS_0": "c",
+	}))
+	have, exists := cfg.GetListFlexible("SERVERS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"a", "b"})
+}
+
+func (t *TestSuite) TestGetListFlexibleIndexed() {
+	cfg := New(WithEnviron(map[string]string{
+		"SERVERS_0": "c",
+		"SERVERS_1": "d",
+	}))
+	have, exists := cfg.GetListFlexible("SERVERS", nil)
+	t.True(exists)
+	t.Equal(have, []string{"c", "d"})
+}
+
+func (t *TestSuite) TestGetListFlexibleNotExists() {
+	expected := []string{"default"}
+	cfg := New(WithEnviron(map[string]string{}))
+	have, exists := cfg.GetListFlexible("SERVERS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLinesFromFileExists() {
+	expected := []string{"alice", "bob", "carol"}
+	path := filepath.Join(t.T().TempDir(), "allow.txt")
+	t.NoError(os.WriteFile(path, []byte("# allowed users\nalice\n\n  bob  \r\ncarol"), 0o600))
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", path))
+	have, exists := GetLinesFromFile("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLinesFromFileMissingFile() {
+	expected := []string{"default"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", filepath.Join(t.T().TempDir(), "missing.txt")))
+	have, exists := GetLinesFromFile("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetLinesFromFileNotExists() {
+	expected := []string{"default"}
+	have, exists := GetLinesFromFile("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCentsExists() {
+	for val, expected := range map[string]int64{
+		"19.99": 1999,
+		"20":    2000,
+		"0.5":   50,
+		"-1.25": -125,
+	} {
+		t.NoError(os.Setenv("TEST_VAR_EXISTS", val))
+		have, exists := GetCents("TEST_VAR_EXISTS", 0)
+		t.True(exists, val)
+		t.Equal(have, expected, val)
+	}
+}
+
+func (t *TestSuite) TestGetCentsTooManyDigits() {
+	expected := int64(100)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "1.234"))
+	have, exists := GetCents("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCentsParseFailure() {
+	expected := int64(100)
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "$1.00"))
+	have, exists := GetCents("TEST_VAR_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCentsNotExists() {
+	expected := int64(100)
+	have, exists := GetCents("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringFirstNExact() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c"))
+	have, exists := GetCSVStringFirstN("TEST_VAR_EXISTS", nil, 3)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringFirstNTruncated() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c,d,e"))
+	have, exists := GetCSVStringFirstN("TEST_VAR_EXISTS", nil, 3)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringFirstNTooFew() {
+	expected := []string{"x", "y", "z"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b"))
+	have, exists := GetCSVStringFirstN("TEST_VAR_EXISTS", expected, 3)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringFirstNNegative() {
+	expected := []string{"x"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b"))
+	have, exists := GetCSVStringFirstN("TEST_VAR_EXISTS", expected, -1)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringFirstNNotExists() {
+	expected := []string{"x", "y", "z"}
+	have, exists := GetCSVStringFirstN("TEST_VAR_NOT_EXISTS", expected, 3)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetWithSourcesEnv() {
+	path := filepath.Join(t.T().TempDir(), "password")
+	t.NoError(os.WriteFile(path, []byte("from-file\n"), 0o600))
+	cfg := New(WithEnviron(map[string]string{
+		"PASSWORD":      "from-env",
+		"PASSWORD_FILE": path,
+	}))
+
+	have, source, exists := cfg.GetWithSources("PASSWORD", "default")
+	t.True(exists)
+	t.Equal(source, SourceEnv)
+	t.Equal(have, "from-env")
+}
+
+func (t *TestSuite) TestGetWithSourcesFile() {
+	path := filepath.Join(t.T().TempDir(), "password")
+	t.NoError(os.WriteFile(path, []byte("from-file\n"), 0o600))
+	cfg := New(WithEnviron(map[string]string{
+		"PASSWORD_FILE": path,
+	}))
+
+	have, source, exists := cfg.GetWithSources("PASSWORD", "default")
+	t.True(exists)
+	t.Equal(source, SourceFile)
+	t.Equal(have, "from-file")
+}
+
+func (t *TestSuite) TestGetWithSourcesDefault() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	have, source, exists := cfg.GetWithSources("PASSWORD", "default")
+	t.False(exists)
+	t.Equal(source, SourceDefault)
+	t.Equal(have, "default")
+}
+
+func (t *TestSuite) TestGetWithSourcesUnreadableFile() {
+	cfg := New(WithEnviron(map[string]string{
+		"PASSWORD_FILE": filepath.Join(t.T().TempDir(), "missing"),
+	}))
+
+	have, source, exists := cfg.GetWithSources("PASSWORD", "default")
+	t.False(exists)
+	t.Equal(source, SourceDefault)
+	t.Equal(have, "default")
+}
+
+func (t *TestSuite) TestGetSecretEnv() {
+	cfg := New(WithEnviron(map[string]string{"TOKEN": "from-env"}))
+
+	have, exists := cfg.GetSecret("TOKEN", "default")
+	t.True(exists)
+	t.Equal(have, "from-env")
+}
+
+func (t *TestSuite) TestGetSecretNotExists() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetSecret("TOKEN", "default")
+	t.False(exists)
+	t.Equal(have, "default")
+}
+
+func (t *TestSuite) TestWithSecretTrim() {
+	for _, tc := range []struct {
+		mode, content, expected string
+	}{
+		{SecretTrimNewline, "s3cr3t\n", "s3cr3t"},
+		{SecretTrimNewline, "s3cr3t\r\n", "s3cr3t"},
+		{SecretTrimNewline, "s3cr3t  \n", "s3cr3t  "},
+		{SecretTrimNewline, "s3 cr3t\n\n", "s3 cr3t\n"},
+		{SecretTrimSpace, "s3cr3t\n", "s3cr3t"},
+		{SecretTrimSpace, " s3cr3t  \n", "s3cr3t"},
+		{SecretTrimSpace, "s3 cr3t\n", "s3 cr3t"},
+		{SecretTrimNone, "s3cr3t\n", "s3cr3t\n"},
+		{SecretTrimNone, "s3cr3t  ", "s3cr3t  "},
+		{SecretTrimNone, "s3 cr3t", "s3 cr3t"},
+	} {
+		path := filepath.Join(t.T().TempDir(), "token")
+		t.NoError(os.WriteFile(path, []byte(tc.content), 0o600))
+		cfg := New(WithSecretTrim(tc.mode), WithEnviron(map[string]string{
+			"TOKEN_FILE": path,
+		}))
+
+		have, exists := cfg.GetSecret("TOKEN", "")
+		t.True(exists)
+		t.Equal(have, tc.expected, "mode %s, content %q", tc.mode, tc.content)
+	}
+}
+
+func (t *TestSuite) TestWithSecretTrimDefault() {
+	dir := t.T().TempDir()
+	t.NoError(os.WriteFile(filepath.Join(dir, "TOKEN"), []byte(" s3cr3t \n"), 0o600))
+	cfg := New(WithFileDir(dir), WithEnviron(map[string]string{}))
+
+	have, exists := cfg.GetString("TOKEN", "")
+	t.True(exists)
+	t.Equal(have, " s3cr3t ")
+}
+
+func (t *TestSuite) TestWithSecretTrimUnknownMode() {
+	t.Panics(func() { WithSecretTrim("trailing") })
+	t.Panics(func() { WithSecretTrim("") })
+}
+
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func (t *TestSuite) TestGetOrderedInt() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "8"))
+	have, exists := GetOrdered("TEST_VAR_EXISTS", 4, 1, 16, strconv.Atoi)
+	t.True(exists)
+	t.Equal(have, 8)
+}
+
+func (t *TestSuite) TestGetOrderedFloat() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "0.75"))
+	have, exists := GetOrdered("TEST_VAR_EXISTS", 0.5, 0, 1, parseFloat64)
+	t.True(exists)
+	t.Equal(have, 0.75)
+}
+
+func (t *TestSuite) TestGetOrderedExistsMax() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "64"))
+	have, exists := GetOrdered("TEST_VAR_EXISTS", 4, 1, 16, strconv.Atoi)
+	t.True(exists)
+	t.Equal(have, 16)
+}
+
+func (t *TestSuite) TestGetOrderedExistsMin() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "-1.5"))
+	have, exists := GetOrdered("TEST_VAR_EXISTS", 0.5, 0, 1, parseFloat64)
+	t.True(exists)
+	t.Equal(have, 0.0)
+}
+
+func (t *TestSuite) TestGetOrderedParseFailure() {
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "many"))
+	have, exists := GetOrdered("TEST_VAR_EXISTS", 4, 1, 16, strconv.Atoi)
+	t.False(exists)
+	t.Equal(have, 4)
+}
+
+func (t *TestSuite) TestGetPtrExists() {
+	cfg := New(WithEnviron(map[string]string{
+		"REGION": "",
+		"PORT":   "9000",
+		"DEBUG":  "false",
+	}))
+
+	region := cfg.GetStringPtr("REGION")
+	t.NotNil(region)
+	t.Equal(*region, "")
+
+	port := cfg.GetIntPtr("PORT")
+	t.NotNil(port)
+	t.Equal(*port, 9000)
+
+	debug := cfg.GetBoolPtr("DEBUG")
+	t.NotNil(debug)
+	t.False(*debug)
+}
+
+func (t *TestSuite) TestGetPtrNotExists() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	t.Nil(cfg.GetStringPtr("REGION"))
+	t.Nil(cfg.GetIntPtr("PORT"))
+	t.Nil(cfg.GetBoolPtr("DEBUG"))
+}
+
+func (t *TestSuite) TestGetPtrParseFailure() {
+	cfg := New(WithEnviron(map[string]string{
+		"PORT":  "eighty",
+		"DEBUG": "sometimes",
+	}))
+
+	t.Nil(cfg.GetIntPtr("PORT"))
+	t.Nil(cfg.GetBoolPtr("DEBUG"))
+}
+
+func (t *TestSuite) TestGetFuncExists() {
+	cfg := New(WithEnviron(map[string]string{
+		"HOST":  "example.com",
+		"PORT":  "9000",
+		"DEBUG": "true",
+	}))
+	called := false
+
+	host, exists := cfg.GetStringFunc("HOST", func() string {
+		called = true
+		return "localhost"
+	})
+	t.True(exists)
+	t.Equal(host, "example.com")
+
+	port, exists := cfg.GetIntFunc("PORT", func() int {
+		called = true
+		return 8080
+	})
+	t.True(exists)
+	t.Equal(port, 9000)
+
+	debug, exists := cfg.GetBoolFunc("DEBUG", func() bool {
+		called = true
+		return false
+	})
+	t.True(exists)
+	t.True(debug)
+
+	t.False(called)
+}
+
+func (t *TestSuite) TestGetFuncNotExists() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	host, exists := cfg.GetStringFunc("HOST", func() string { return "localhost" })
+	t.False(exists)
+	t.Equal(host, "localhost")
+
+	port, exists := cfg.GetIntFunc("PORT", func() int { return 8080 })
+	t.False(exists)
+	t.Equal(port, 8080)
+
+	debug, exists := cfg.GetBoolFunc("DEBUG", func() bool { return true })
+	t.False(exists)
+	t.True(debug)
+}
+
+func (t *TestSuite) TestGetFuncParseFailure() {
+	cfg := New(WithEnviron(map[string]string{"PORT": "eighty"}))
+
+	port, exists := cfg.GetIntFunc("PORT", func() int { return 8080 })
+	t.False(exists)
+	t.Equal(port, 8080)
+}
+
+func (t *TestSuite) TestGetCSVStringStripCommentsExists() {
+	expected := []string{"a", "b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,c # production set"))
+	have, exists := GetCSVStringStripComments("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringStripCommentsQuoted() {
+	expected := []string{"a", "#b", "c"}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", `a,"#b",c#comment`))
+	have, exists := GetCSVStringStripComments("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringStripCommentsTrailingElement() {
+	expected := []string{"a", "b", ""}
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "a,b,#note"))
+	have, exists := GetCSVStringStripComments("TEST_VAR_EXISTS", nil)
+	t.True(exists)
+	t.Equal(have, expected)
+}
+
+func (t *TestSuite) TestGetCSVStringStripCommentsNotExists() {
+	expected := []string{"default"}
+	have, exists := GetCSVStringStripComments("TEST_VAR_NOT_EXISTS", expected)
+	t.False(exists)
+	t.Equal(have, expected)
+}
+
+func sortedEnviron() []string {
+	env := os.Environ()
+	sort.Strings(env)
+	return env
+}
+
+func (t *TestSuite) TestSnapshot() {
+	t.NoError(os.Setenv("TEST_SNAPSHOT_MODIFIED", "original"))
+	t.NoError(os.Setenv("TEST_SNAPSHOT_UNSET", "original"))
+	defer os.Unsetenv("TEST_SNAPSHOT_MODIFIED")
+	defer os.Unsetenv("TEST_SNAPSHOT_UNSET")
+
+	expected := sortedEnviron()
+	restore := Snapshot()
+
+	t.NoError(os.Setenv("TEST_SNAPSHOT_MODIFIED", "modified"))
+	t.NoError(os.Unsetenv("TEST_SNAPSHOT_UNSET"))
+	t.NoError(os.Setenv("TEST_SNAPSHOT_ADDED", "added"))
+	t.NotEqual(sortedEnviron(), expected)
+
+	restore()
+	t.Equal(sortedEnviron(), expected)
+}
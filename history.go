@@ -0,0 +1,89 @@
+package decouple
+
+import "sync"
+
+// A History remembers the most recent values of variables, so that
+// callers can roll back to an earlier value after a bad change.
+type History struct {
+	config *Config
+	size   int
+
+	mu     sync.Mutex
+	values map[string][]string
+}
+
+// NewHistory returns a new History that keeps up to size values for
+// each variable (at least two), looking up variables using the
+// package-level settings.
+func NewHistory(size int) *History {
+	return newHistory(nil, size)
+}
+
+// NewHistory is like the package-level NewHistory, but the returned
+// History looks up variables using the settings in c.
+func (c *Config) NewHistory(size int) *History {
+	return newHistory(c, size)
+}
+
+func newHistory(c *Config, size int) *History {
+	if size < 2 {
+		size = 2
+	}
+
+	return &History{
+		config: c,
+		size:   size,
+		values: make(map[string][]string),
+	}
+}
+
+// Record returns the value of an environment variable as a string, as
+// GetString would, and records it if it differs from the most recently
+// recorded value. Once size values have been recorded for name, the
+// oldest is discarded.
+//
+// Example:
+//
+//	history := decouple.NewHistory(5)
+//	for range reloaded {
+//		apply(history.Record("UPSTREAM", "localhost:8080"))
+//	}
+func (h *History) Record(name, defval string) string {
+	c := h.config
+	if c == nil {
+		c = defaultConfig
+	}
+
+	val, _ := c.GetString(name, defval)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values := h.values[name]
+	if len(values) > 0 && values[len(values)-1] == val {
+		return val
+	}
+
+	values = append(values, val)
+	if len(values) > h.size {
+		values = values[len(values)-h.size:]
+	}
+	h.values[name] = values
+
+	return val
+}
+
+// Previous returns the value recorded for name before the most recent
+// one. If fewer than two values have been recorded, it returns ("",
+// false).
+func (h *History) Previous(name string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values := h.values[name]
+	if len(values) < 2 {
+		return "", false
+	}
+
+	return values[len(values)-2], true
+}
@@ -0,0 +1,36 @@
+package decouple
+
+func (t *TestSuite) TestHistoryPrevious() {
+	env := map[string]string{}
+	history := New(WithEnviron(env)).NewHistory(3)
+
+	for _, val := range []string{"one", "two", "two", "three"} {
+		env["UPSTREAM"] = val
+		t.Equal(history.Record("UPSTREAM", "default"), val)
+	}
+
+	have, ok := history.Previous("UPSTREAM")
+	t.True(ok)
+	t.Equal(have, "two")
+}
+
+func (t *TestSuite) TestHistoryBounded() {
+	env := map[string]string{}
+	history := New(WithEnviron(env)).NewHistory(2)
+
+	for _, val := range []string{"one", "two", "three"} {
+		env["UPSTREAM"] = val
+		history.Record("UPSTREAM", "default")
+	}
+
+	t.Equal(history.values["UPSTREAM"], []string{"two", "three"})
+}
+
+func (t *TestSuite) TestHistorySingleValue() {
+	history := New(WithEnviron(map[string]string{"UPSTREAM": "one"})).NewHistory(3)
+	history.Record("UPSTREAM", "default")
+
+	have, ok := history.Previous("UPSTREAM")
+	t.False(ok)
+	t.Equal(have, "")
+}
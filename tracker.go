@@ -0,0 +1,59 @@
+package decouple
+
+import "sync"
+
+// A Tracker remembers the values of variables between reads so that
+// callers can tell when a setting has changed, for example after
+// reloading configuration with Overload. The zero value is ready to
+// use and looks up variables using the package-level settings.
+type Tracker struct {
+	config *Config
+
+	mu   sync.Mutex
+	last map[string]string
+}
+
+// NewTracker returns a new Tracker that looks up variables using the
+// package-level settings.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// NewTracker is like the package-level NewTracker, but the returned
+// Tracker looks up variables using the settings in c.
+func (c *Config) NewTracker() *Tracker {
+	return &Tracker{config: c}
+}
+
+// Changed returns the value of an environment variable as a string,
+// as GetString would, and reports whether it differs from the value
+// returned by the previous call to Changed for the same name. The
+// first call for each name always reports a change.
+//
+// Example:
+//
+//	tracker := decouple.NewTracker()
+//	for range reloaded {
+//		if level, changed := tracker.Changed("LOG_LEVEL", "info"); changed {
+//			setLogLevel(level)
+//		}
+//	}
+func (t *Tracker) Changed(name, defval string) (value string, changed bool) {
+	c := t.config
+	if c == nil {
+		c = defaultConfig
+	}
+
+	value, _ = c.GetString(name, defval)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last, seen := t.last[name]
+	if t.last == nil {
+		t.last = make(map[string]string)
+	}
+	t.last[name] = value
+
+	return value, !seen || last != value
+}
@@ -0,0 +1,39 @@
+package decouple
+
+import "os"
+
+func (t *TestSuite) TestTrackerChanged() {
+	tracker := NewTracker()
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "one"))
+
+	have, changed := tracker.Changed("TEST_VAR_EXISTS", "")
+	t.True(changed)
+	t.Equal(have, "one")
+
+	have, changed = tracker.Changed("TEST_VAR_EXISTS", "")
+	t.False(changed)
+	t.Equal(have, "one")
+
+	t.NoError(os.Setenv("TEST_VAR_EXISTS", "two"))
+	have, changed = tracker.Changed("TEST_VAR_EXISTS", "")
+	t.True(changed)
+	t.Equal(have, "two")
+}
+
+func (t *TestSuite) TestTrackerChangedWithConfig() {
+	env := map[string]string{"SETTING": "one"}
+	tracker := New(WithEnviron(env)).NewTracker()
+
+	have, changed := tracker.Changed("SETTING", "")
+	t.True(changed)
+	t.Equal(have, "one")
+
+	have, changed = tracker.Changed("SETTING", "")
+	t.False(changed)
+	t.Equal(have, "one")
+
+	delete(env, "SETTING")
+	have, changed = tracker.Changed("SETTING", "default")
+	t.True(changed)
+	t.Equal(have, "default")
+}
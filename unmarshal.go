@@ -0,0 +1,193 @@
+package decouple
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	fieldConvertersLock sync.RWMutex
+	fieldConverters     = map[reflect.Type]func(string) (interface{}, error){}
+)
+
+// RegisterFieldConverter registers a function that Unmarshal uses to
+// convert values for struct fields of type t. A registered converter
+// takes precedence over the built-in conversions, which makes it
+// possible to populate fields of types such as *url.URL or net.IP.
+// The value returned by fn must be assignable to t.
+//
+// Example:
+//
+//	decouple.RegisterFieldConverter(reflect.TypeOf(&url.URL{}),
+//		func(s string) (interface{}, error) {
+//			return url.Parse(s)
+//		})
+func RegisterFieldConverter(t reflect.Type, fn func(string) (interface{}, error)) {
+	fieldConvertersLock.Lock()
+	defer fieldConvertersLock.Unlock()
+
+	fieldConverters[t] = fn
+}
+
+// resetFieldConverters discards all registered converters.
+func resetFieldConverters() {
+	fieldConvertersLock.Lock()
+	defer fieldConvertersLock.Unlock()
+
+	fieldConverters = map[reflect.Type]func(string) (interface{}, error){}
+}
+
+func lookupFieldConverter(t reflect.Type) (func(string) (interface{}, error), bool) {
+	fieldConvertersLock.RLock()
+	defer fieldConvertersLock.RUnlock()
+
+	fn, ok := fieldConverters[t]
+	return fn, ok
+}
+
+// Unmarshal populates the fields of the struct pointed to by v from
+// environment variables. Each field is read from the variable named
+// by its "env" struct tag; fields without a tag are ignored, and
+// fields whose variable does not exist are left unchanged.
+//
+// Fields whose type has a converter registered with
+// RegisterFieldConverter are set using that converter. Otherwise,
+// fields of type string, bool, any integer or floating point type,
+// time.Duration, and []string (parsed as a single CSV row) are
+// supported. If a value cannot be converted, Unmarshal returns an
+// error that names the field and the variable.
+//
+// Example:
+//
+//	type Settings struct {
+//		Host    string        `env:"HOST"`
+//		Port    int           `env:"PORT"`
+//		Timeout time.Duration `env:"TIMEOUT"`
+//	}
+//
+//	settings := Settings{Host: "localhost", Port: 8080}
+//	if err := decouple.Unmarshal(&settings); err != nil {
+//		log.Fatal(err)
+//	}
+func Unmarshal(v interface{}) error {
+	return defaultConfig.Unmarshal(v)
+}
+
+// Unmarshal is like the package-level Unmarshal, but looks up
+// variables using the settings in c.
+func (c *Config) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("decouple: Unmarshal requires a non-nil pointer to a struct")
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := field.Tag.Lookup("env")
+		if !ok || name == "" || field.PkgPath != "" {
+			continue
+		}
+
+		val, exists := c.LookupEnv(name)
+		if !exists {
+			continue
+		}
+
+		if err := setField(rv.Field(i), val); err != nil {
+			c.parseError(name, val, err)
+			return fmt.Errorf("decouple: field %s (%s): %w", field.Name, c.fullName(name), err)
+		}
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField converts val to the type of fv and stores the result in
+// fv.
+func setField(fv reflect.Value, val string) error {
+	if fn, ok := lookupFieldConverter(fv.Type()); ok {
+		ret, err := fn(val)
+		if err != nil {
+			return err
+		}
+
+		rv := reflect.ValueOf(ret)
+		if !rv.IsValid() {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+
+		if !rv.Type().AssignableTo(fv.Type()) {
+			return fmt.Errorf("converter returned %s, expected %s", rv.Type(), fv.Type())
+		}
+
+		fv.Set(rv)
+		return nil
+	}
+
+	if fv.Type() == durationType {
+		ret, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(int64(ret))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		ret, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(ret)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ret, err := strconv.ParseInt(val, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(ret)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ret, err := strconv.ParseUint(val, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(ret)
+	case reflect.Float32, reflect.Float64:
+		ret, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(ret)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", fv.Type())
+		}
+		rec, err := csv.NewReader(strings.NewReader(val)).Read()
+		if err != nil {
+			return err
+		}
+		ret := reflect.MakeSlice(fv.Type(), len(rec), len(rec))
+		for i, elem := range rec {
+			ret.Index(i).SetString(elem)
+		}
+		fv.Set(ret)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+
+	return nil
+}
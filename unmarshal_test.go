@@ -0,0 +1,96 @@
+package decouple
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+type testSettings struct {
+	Host     string        `env:"HOST"`
+	Port     int           `env:"PORT"`
+	Debug    bool          `env:"DEBUG"`
+	Ratio    float64       `env:"RATIO"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Tags     []string      `env:"TAGS"`
+	Endpoint *url.URL      `env:"ENDPOINT"`
+	Ignored  string
+}
+
+// unregisterFieldConverter removes the converter for t so that tests
+// registering one do not leak it into the rest of the suite.
+func unregisterFieldConverter(t reflect.Type) {
+	fieldConvertersLock.Lock()
+	defer fieldConvertersLock.Unlock()
+
+	delete(fieldConverters, t)
+}
+
+func parseTestURL(s string) (interface{}, error) {
+	return url.ParseRequestURI(s)
+}
+
+func (t *TestSuite) TestUnmarshal() {
+	RegisterFieldConverter(reflect.TypeOf(&url.URL{}), parseTestURL)
+	defer unregisterFieldConverter(reflect.TypeOf(&url.URL{}))
+	cfg := New(WithPrefix("APP_"), WithEnviron(map[string]string{
+		"APP_HOST":     "example.com",
+		"APP_PORT":     "9000",
+		"APP_DEBUG":    "true",
+		"APP_RATIO":    "0.5",
+		"APP_TIMEOUT":  "30s",
+		"APP_TAGS":     "a,b",
+		"APP_ENDPOINT": "https://example.com/api",
+		"APP_IGNORED":  "ignored",
+	}))
+
+	settings := testSettings{Host: "localhost"}
+	t.NoError(cfg.Unmarshal(&settings))
+	t.Equal(settings.Host, "example.com")
+	t.Equal(settings.Port, 9000)
+	t.True(settings.Debug)
+	t.Equal(settings.Ratio, 0.5)
+	t.Equal(settings.Timeout, 30*time.Second)
+	t.Equal(settings.Tags, []string{"a", "b"})
+	t.Equal(settings.Endpoint.String(), "https://example.com/api")
+	t.Equal(settings.Ignored, "")
+}
+
+func (t *TestSuite) TestUnmarshalDefaults() {
+	cfg := New(WithEnviron(map[string]string{}))
+
+	settings := testSettings{Host: "localhost", Port: 8080}
+	t.NoError(cfg.Unmarshal(&settings))
+	t.Equal(settings.Host, "localhost")
+	t.Equal(settings.Port, 8080)
+}
+
+func (t *TestSuite) TestUnmarshalConverterError() {
+	RegisterFieldConverter(reflect.TypeOf(&url.URL{}), parseTestURL)
+	defer unregisterFieldConverter(reflect.TypeOf(&url.URL{}))
+	cfg := New(WithEnviron(map[string]string{
+		"ENDPOINT": "not a url",
+	}))
+
+	var settings testSettings
+	err := cfg.Unmarshal(&settings)
+	t.Error(err)
+	t.Contains(err.Error(), "Endpoint")
+	t.Contains(err.Error(), "ENDPOINT")
+}
+
+func (t *TestSuite) TestUnmarshalParseError() {
+	cfg := New(WithEnviron(map[string]string{
+		"PORT": "eighty",
+	}))
+
+	var settings testSettings
+	err := cfg.Unmarshal(&settings)
+	t.Error(err)
+	t.Contains(err.Error(), "Port")
+}
+
+func (t *TestSuite) TestUnmarshalNotStruct() {
+	var port int
+	t.Error(Unmarshal(&port))
+}
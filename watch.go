@@ -0,0 +1,131 @@
+package decouple
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+var (
+	// watchInterval is how often Watch checks files for changes.
+	watchInterval = 250 * time.Millisecond
+
+	// watchDebounce is how long files must remain unchanged before
+	// Watch reloads them.
+	watchDebounce = 500 * time.Millisecond
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(filenames []string) ([]fileState, error) {
+	states := make([]fileState, len(filenames))
+
+	for i, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		states[i] = fileState{info.ModTime(), info.Size()}
+	}
+
+	return states, nil
+}
+
+func sameFileStates(a, b []fileState) bool {
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Watch watches the named dotenv files (or '.env' if no filenames are
+// provided) for changes. When a change is detected, Watch reloads the
+// files using Overload and sends a notification on the returned
+// channel; consumers should then call the Get* functions again to
+// pick up new values.
+//
+// Changes are debounced, so that a burst of writes results in a
+// single reload. If a notification has not been received by the time
+// the next one is ready, the two are merged. The channel is closed
+// when ctx is cancelled.
+//
+// Watch returns an error if any of the named files cannot be
+// accessed when it is called. Errors encountered while reloading are
+// ignored, and no notification is sent for that change.
+//
+// Example:
+//
+//	changed, err := decouple.Watch(ctx, ".env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for range changed {
+//		logLevel, _ = decouple.GetString("LOG_LEVEL", "info")
+//	}
+func Watch(ctx context.Context, filenames ...string) (<-chan struct{}, error) {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	state, err := statFiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		// lastChange is the time at which we last saw a change that
+		// has not been reloaded yet, or the zero value if there are no
+		// pending changes.
+		var lastChange time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				cur, err := statFiles(filenames)
+				if err != nil {
+					// Files may briefly disappear while an editor
+					// replaces them; try again on the next tick.
+					continue
+				}
+
+				if !sameFileStates(cur, state) {
+					state = cur
+					lastChange = now
+					continue
+				}
+
+				if lastChange.IsZero() || now.Sub(lastChange) < watchDebounce {
+					continue
+				}
+
+				lastChange = time.Time{}
+				if err := Overload(filenames...); err != nil {
+					continue
+				}
+
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,71 @@
+package decouple
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (t *TestSuite) setWatchTiming(interval, debounce time.Duration) {
+	oldInterval, oldDebounce := watchInterval, watchDebounce
+	watchInterval, watchDebounce = interval, debounce
+	t.T().Cleanup(func() {
+		watchInterval, watchDebounce = oldInterval, oldDebounce
+	})
+}
+
+func (t *TestSuite) TestWatchNotifiesOnce() {
+	t.setWatchTiming(10*time.Millisecond, 100*time.Millisecond)
+	envfile := filepath.Join(t.T().TempDir(), ".env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_WATCH_VAR=one\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed, err := Watch(ctx, envfile)
+	t.NoError(err)
+
+	for _, val := range []string{"two", "three", "four"} {
+		t.NoError(os.WriteFile(envfile, []byte("TEST_WATCH_VAR="+val+"\n"), 0o600))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.FailNow("timed out waiting for notification")
+	}
+
+	have, exists := GetString("TEST_WATCH_VAR", "")
+	t.True(exists)
+	t.Equal(have, "four")
+
+	select {
+	case <-changed:
+		t.Fail("received more than one notification")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func (t *TestSuite) TestWatchStopsOnCancel() {
+	t.setWatchTiming(10*time.Millisecond, 100*time.Millisecond)
+	envfile := filepath.Join(t.T().TempDir(), ".env")
+	t.NoError(os.WriteFile(envfile, []byte("TEST_WATCH_VAR=one\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed, err := Watch(ctx, envfile)
+	t.NoError(err)
+	cancel()
+
+	select {
+	case _, ok := <-changed:
+		t.False(ok)
+	case <-time.After(2 * time.Second):
+		t.FailNow("timed out waiting for channel to close")
+	}
+}
+
+func (t *TestSuite) TestWatchMissingFile() {
+	_, err := Watch(context.Background(), filepath.Join(t.T().TempDir(), "missing.env"))
+	t.Error(err)
+}